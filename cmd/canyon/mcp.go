@@ -12,6 +12,7 @@ import (
 
 	"github.com/humanitec/canyon-cli/internal/mcp"
 	"github.com/humanitec/canyon-cli/internal/mcp/tools"
+	"github.com/humanitec/canyon-cli/internal/render"
 	"github.com/humanitec/canyon-cli/internal/rpc"
 )
 
@@ -22,6 +23,22 @@ var mcpCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
 
+		if serve, _ := cmd.Flags().GetBool("serve-renders"); serve {
+			addr, _ := cmd.Flags().GetString("render-addr")
+			dir, _ := cmd.Flags().GetString("render-dir")
+			allowRemote, _ := cmd.Flags().GetBool("render-allow-remote")
+			store, err := render.NewServerStore(addr, dir, allowRemote)
+			if err != nil {
+				return err
+			}
+			go func() {
+				if err := store.Serve(cmd.Context()); err != nil {
+					slog.Error("Render server failed", slog.Any("err", err))
+				}
+			}()
+			tools.SetRenderStore(store)
+		}
+
 		h := mcp.AsHandler(tools.New())
 		h = rpc.RecoveryMiddleware(h)
 		h = rpc.LoggingMiddleware(h)
@@ -74,5 +91,9 @@ var mcpCmd = &cobra.Command{
 }
 
 func init() {
+	mcpCmd.Flags().Bool("serve-renders", false, "Serve rendered artifacts from an embedded HTTP server on localhost instead of the configured render store")
+	mcpCmd.Flags().String("render-addr", "127.0.0.1:0", "The address of the embedded render server, port 0 picks a random free port")
+	mcpCmd.Flags().String("render-dir", "", "Optional directory to keep the renders of the embedded render server in rather than memory. Only the most recent 100 renders are kept")
+	mcpCmd.Flags().Bool("render-allow-remote", false, "Allow a render-addr that is reachable from the network, the renders are served without authentication")
 	rootCmd.AddCommand(mcpCmd)
 }
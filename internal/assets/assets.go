@@ -0,0 +1,149 @@
+// Package assets holds the third party scripts, stylesheets, fonts, and images used by the render templates. When the
+// assets have been fetched into the dist directory with go generate, they are embedded in the binary and inlined into
+// the rendered HTML so that renders are fully self-contained and work on air-gapped networks without leaking viewing
+// activity to CDNs. Assets that have not been fetched fall back to the CDN link.
+//
+// Every asset url is pinned to an explicit version and the sha256 checksum of each fetched file is recorded in
+// assets.sum, so that go generate fails instead of embedding content that changed on the CDN.
+package assets
+
+import (
+	"embed"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"strings"
+)
+
+//go:generate go run fetch.go
+
+// Dir is the directory, relative to this package, that fetched assets are written to and embedded from.
+const Dir = "dist"
+
+// ChecksumsFile is the file, relative to this package, that records the sha256 checksums of the fetched assets in the
+// format of sha256sum.
+const ChecksumsFile = "assets.sum"
+
+// Asset is a third party asset referenced by the render templates.
+type Asset struct {
+	// File is the name of the fetched file in the dist directory.
+	File string
+	// URL is the CDN url the asset is fetched from and falls back to.
+	URL string
+	// ContentType is the media type of the asset.
+	ContentType string
+}
+
+const archivoFontUrl = "https://fonts.googleapis.com/css2?family=Archivo:ital,wght@0,100..900;1,100..900&display=swap"
+
+// Assets are the known assets by name. The urls must be pinned to a version so that the fetched content matches the
+// recorded checksum.
+var Assets = map[string]Asset{
+	"tailwind":     {File: "tailwind.js", URL: "https://cdn.tailwindcss.com/3.4.16", ContentType: "text/javascript"},
+	"ag-grid":      {File: "ag-grid-community.min.js", URL: "https://cdn.jsdelivr.net/npm/ag-grid-community@32.3.3/dist/ag-grid-community.min.js", ContentType: "text/javascript"},
+	"d3":           {File: "d3.min.js", URL: "https://cdnjs.cloudflare.com/ajax/libs/d3/7.8.5/d3.min.js", ContentType: "text/javascript"},
+	"archivo-font": {File: "archivo.css", URL: archivoFontUrl, ContentType: "text/css"},
+	"logo-light":   {File: "light_logo.png", URL: "https://cdn.glitch.global/1f44bda7-6694-4547-8f1b-1fa1f48b5711/light_logo.png?v=1743423313930", ContentType: "image/png"},
+	"logo-dark":    {File: "canyon.png", URL: "https://cdn.glitch.global/1f44bda7-6694-4547-8f1b-1fa1f48b5711/canyon.png?v=1743423174793", ContentType: "image/png"},
+}
+
+//go:embed all:dist
+var embedded embed.FS
+
+//go:embed assets.sum
+var checksums string
+
+// Checksums returns the recorded sha256 checksums of the fetched assets by file name.
+func Checksums() (map[string]string, error) {
+	return parseChecksums(checksums)
+}
+
+func parseChecksums(raw string) (map[string]string, error) {
+	out := make(map[string]string)
+	for i, line := range strings.Split(raw, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		sum, file, ok := strings.Cut(line, "  ")
+		if !ok || len(sum) != 64 || file == "" {
+			return nil, fmt.Errorf("invalid checksum on line %d of %s", i+1, ChecksumsFile)
+		}
+		out[file] = sum
+	}
+	return out, nil
+}
+
+// vendored is the file system the fetched assets are read from. It is a variable so that tests can replace it.
+var vendored fs.FS = func() fs.FS {
+	sub, err := fs.Sub(embedded, Dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}()
+
+func lookup(name string) (Asset, []byte, error) {
+	a, ok := Assets[name]
+	if !ok {
+		return Asset{}, nil, fmt.Errorf("unknown asset '%s'", name)
+	}
+	raw, err := fs.ReadFile(vendored, a.File)
+	if err != nil || len(raw) == 0 {
+		return a, nil, nil
+	}
+	return a, raw, nil
+}
+
+// Vendored returns whether the named asset is embedded in the binary.
+func Vendored(name string) bool {
+	_, raw, _ := lookup(name)
+	return raw != nil
+}
+
+// Script returns a script element with the named script inlined, or loaded from the CDN if it is not embedded.
+func Script(name string) (template.HTML, error) {
+	a, raw, err := lookup(name)
+	if err != nil {
+		return "", err
+	} else if raw == nil {
+		return template.HTML(fmt.Sprintf(`<script src="%s"></script>`, template.HTMLEscapeString(a.URL))), nil
+	}
+	return template.HTML("<script>" + escapeClosingTag(string(raw), "script") + "</script>"), nil
+}
+
+// Stylesheet returns a style element with the named stylesheet inlined, or a link to the CDN if it is not embedded.
+func Stylesheet(name string) (template.HTML, error) {
+	a, raw, err := lookup(name)
+	if err != nil {
+		return "", err
+	} else if raw == nil {
+		return template.HTML(fmt.Sprintf(`<link href="%s" rel="stylesheet" />`, template.HTMLEscapeString(a.URL))), nil
+	}
+	return template.HTML("<style>" + escapeClosingTag(string(raw), "style") + "</style>"), nil
+}
+
+// Src returns a data url of the named asset for use in src attributes, or the CDN url if it is not embedded.
+func Src(name string) (template.URL, error) {
+	a, raw, err := lookup(name)
+	if err != nil {
+		return "", err
+	} else if raw == nil {
+		return template.URL(a.URL), nil
+	}
+	return template.URL("data:" + a.ContentType + ";base64," + base64.StdEncoding.EncodeToString(raw)), nil
+}
+
+// FuncMap returns the template functions for referencing assets: script, stylesheet, and assetSrc.
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"script":     Script,
+		"stylesheet": Stylesheet,
+		"assetSrc":   Src,
+	}
+}
+
+// escapeClosingTag prevents inlined content from closing the surrounding element early.
+func escapeClosingTag(content string, tag string) string {
+	return strings.ReplaceAll(content, "</"+tag, `<\/`+tag)
+}
@@ -0,0 +1,103 @@
+package assets
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func withVendored(t *testing.T, files fstest.MapFS) {
+	previous := vendored
+	vendored = files
+	t.Cleanup(func() { vendored = previous })
+}
+
+func TestFallbackToCdn(t *testing.T) {
+	withVendored(t, fstest.MapFS{})
+	assert.False(t, Vendored("d3"))
+	s, err := Script("d3")
+	assert.NoError(t, err)
+	assert.Equal(t, template.HTML(`<script src="https://cdnjs.cloudflare.com/ajax/libs/d3/7.8.5/d3.min.js"></script>`), s)
+	css, err := Stylesheet("archivo-font")
+	assert.NoError(t, err)
+	assert.Contains(t, string(css), `<link href="https://fonts.googleapis.com/css2?family=Archivo`)
+	src, err := Src("logo-dark")
+	assert.NoError(t, err)
+	assert.Equal(t, template.URL(Assets["logo-dark"].URL), src)
+}
+
+func TestInlineVendored(t *testing.T) {
+	withVendored(t, fstest.MapFS{
+		"d3.min.js":   {Data: []byte(`var x = "</script>";`)},
+		"archivo.css": {Data: []byte(`body { color: red }`)},
+		"canyon.png":  {Data: []byte{0x89, 'P', 'N', 'G'}},
+	})
+	assert.True(t, Vendored("d3"))
+	s, err := Script("d3")
+	assert.NoError(t, err)
+	assert.Equal(t, template.HTML(`<script>var x = "<\/script>";</script>`), s)
+	css, err := Stylesheet("archivo-font")
+	assert.NoError(t, err)
+	assert.Equal(t, template.HTML(`<style>body { color: red }</style>`), css)
+	src, err := Src("logo-dark")
+	assert.NoError(t, err)
+	assert.Equal(t, template.URL("data:image/png;base64,iVBORw=="), src)
+}
+
+func TestUnknownAsset(t *testing.T) {
+	_, err := Script("jquery")
+	assert.EqualError(t, err, "unknown asset 'jquery'")
+}
+
+func TestFuncMapInTemplate(t *testing.T) {
+	withVendored(t, fstest.MapFS{"canyon.png": {Data: []byte{0x89, 'P', 'N', 'G'}}})
+	tmpl := template.Must(template.New("").Funcs(FuncMap()).Parse(`<img src="{{ assetSrc "logo-dark" }}" />{{ script "tailwind" }}`))
+	buffer := new(bytes.Buffer)
+	assert.NoError(t, tmpl.Execute(buffer, nil))
+	assert.Equal(t, `<img src="data:image/png;base64,iVBORw==" /><script src="https://cdn.tailwindcss.com/3.4.16"></script>`, buffer.String())
+}
+
+func TestParseChecksums(t *testing.T) {
+	sum := strings.Repeat("ab", 32)
+	sums, err := parseChecksums(sum + "  d3.min.js\n\n" + sum + "  canyon.png\n")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"d3.min.js": sum, "canyon.png": sum}, sums)
+
+	_, err = parseChecksums(sum + "  d3.min.js\nabc d3.min.js\n")
+	assert.EqualError(t, err, "invalid checksum on line 2 of assets.sum")
+
+	_, err = Checksums()
+	assert.NoError(t, err)
+}
+
+// TestAssetsEmbedded checks that every asset was fetched into the embedded file system with the recorded checksum. It
+// is required in CI and the release build, which run go generate first, and skipped locally when the assets have not
+// been fetched.
+func TestAssetsEmbedded(t *testing.T) {
+	sums, err := Checksums()
+	assert.NoError(t, err)
+	var missing []string
+	for name, a := range Assets {
+		if raw, err := fs.ReadFile(embedded, path.Join(Dir, a.File)); err != nil || len(raw) == 0 {
+			missing = append(missing, name)
+		} else {
+			sum := sha256.Sum256(raw)
+			assert.Equal(t, sums[a.File], hex.EncodeToString(sum[:]), "checksum of %s", a.File)
+		}
+	}
+	slices.Sort(missing)
+	if len(missing) > 0 && os.Getenv("CI") == "" {
+		t.Skipf("assets %v are not embedded, run go generate ./internal/assets", missing)
+	}
+	assert.Empty(t, missing, "assets are not embedded and would be loaded from their CDN")
+}
@@ -0,0 +1,121 @@
+//go:build ignore
+
+// This program fetches the render assets from their CDNs into the dist directory so that they are embedded in the
+// binary. Run it with go generate ./internal/assets. It fails without writing any asset when the checksum of a fetched
+// asset does not match the one recorded in assets.sum. After changing the version of an asset, run it with -update to
+// record the new checksums, and review the change of assets.sum.
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/humanitec/canyon-cli/internal/assets"
+)
+
+// userAgent is a modern browser user agent, Google Fonts only returns woff2 fonts to browsers that support them.
+const userAgent = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0 Safari/537.36"
+
+var cssUrl = regexp.MustCompile(`url\((https://[^)]+)\)`)
+
+var client = &http.Client{Timeout: time.Minute}
+
+func get(u string) ([]byte, string, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, u)
+	}
+	raw, err := io.ReadAll(resp.Body)
+	return raw, resp.Header.Get("Content-Type"), err
+}
+
+// inlineCssUrls replaces the urls in the stylesheet, such as font files, with data urls so the stylesheet is self-contained.
+func inlineCssUrls(css []byte) ([]byte, error) {
+	var err error
+	out := cssUrl.ReplaceAllFunc(css, func(match []byte) []byte {
+		if err != nil {
+			return match
+		}
+		u := string(cssUrl.FindSubmatch(match)[1])
+		raw, contentType, fetchErr := get(u)
+		if fetchErr != nil {
+			err = fetchErr
+			return match
+		}
+		return []byte(fmt.Sprintf("url(data:%s;base64,%s)", contentType, base64.StdEncoding.EncodeToString(raw)))
+	})
+	return out, err
+}
+
+func main() {
+	update := flag.Bool("update", false, "record the checksums of the fetched assets in "+assets.ChecksumsFile+" instead of verifying them")
+	flag.Parse()
+
+	recorded, err := assets.Checksums()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fetched := make(map[string][]byte, len(assets.Assets))
+	sums := make(map[string]string, len(assets.Assets))
+	for name, a := range assets.Assets {
+		raw, _, err := get(a.URL)
+		if err != nil {
+			log.Fatalf("failed to fetch %s: %v", name, err)
+		}
+		if a.ContentType == "text/css" {
+			if raw, err = inlineCssUrls(raw); err != nil {
+				log.Fatalf("failed to inline urls in %s: %v", name, err)
+			}
+		}
+		sum := sha256.Sum256(raw)
+		actual := hex.EncodeToString(sum[:])
+		if expected, ok := recorded[a.File]; !*update && !ok {
+			log.Fatalf("no checksum for %s is recorded in %s, run go run fetch.go -update to record it", a.File, assets.ChecksumsFile)
+		} else if !*update && actual != expected {
+			log.Fatalf("checksum mismatch for %s from %s: expected %s, got %s", a.File, a.URL, expected, actual)
+		}
+		fetched[a.File] = raw
+		sums[a.File] = actual
+	}
+
+	for file, raw := range fetched {
+		if err := os.WriteFile(filepath.Join(assets.Dir, file), raw, 0o644); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("fetched %s (%d bytes)", file, len(raw))
+	}
+	if *update {
+		files := slices.Sorted(maps.Keys(sums))
+		lines := new(strings.Builder)
+		for _, file := range files {
+			_, _ = fmt.Fprintf(lines, "%s  %s\n", sums[file], file)
+		}
+		if err := os.WriteFile(assets.ChecksumsFile, []byte(lines.String()), 0o644); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("recorded the checksums in %s", assets.ChecksumsFile)
+	}
+}
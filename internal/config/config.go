@@ -0,0 +1,107 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the optional canyon configuration file. It allows a platform team to publish organization specific
+// settings to their users alongside the binary.
+type Config struct {
+	// MetadataKeys describes the well known workload and resource metadata keys used in the organization.
+	MetadataKeys []MetadataKey `yaml:"metadataKeys"`
+	// Render configures where the render tools store the rendered artifacts.
+	Render Render `yaml:"render"`
+	// LinkProviders define the external links, such as dashboards or runbooks, shown on the nodes of the tree and
+	// graph renders.
+	LinkProviders []LinkProvider `yaml:"linkProviders"`
+}
+
+type MetadataKey struct {
+	Key         string `yaml:"key"`
+	Description string `yaml:"description"`
+}
+
+// LinkProvider resolves an external link for the render nodes that match its class and metadata key.
+type LinkProvider struct {
+	// Name is the link text, for example "Datadog".
+	Name string `yaml:"name"`
+	// Class limits the provider to nodes of this class such as app, env, or workload. Empty matches all classes.
+	Class string `yaml:"class"`
+	// MetadataKey limits the provider to nodes with this key in their data. The value is available as .Value.
+	MetadataKey string `yaml:"metadataKey"`
+	// Url is a Go template for the link. The node .Id, .Name, .Class, .Data, and .Value are available and are inserted
+	// as is, so escape them with the pathEscape function in path segments and the queryEscape function in query values,
+	// for example https://app.datadoghq.com/apm/services/{{ .Value | pathEscape }}?env={{ .Data.env | queryEscape }}.
+	Url string `yaml:"url"`
+}
+
+type Render struct {
+	// Store is the render store backend: "local", "s3", or "memory". When empty, "s3" is used if the MINIO_* env
+	// variables are set and "local" otherwise.
+	Store string      `yaml:"store"`
+	Local RenderLocal `yaml:"local"`
+	S3    RenderS3    `yaml:"s3"`
+}
+
+type RenderLocal struct {
+	// Dir is the directory to write renders to, defaults to canyon/renders in the user cache directory.
+	Dir string `yaml:"dir"`
+}
+
+// RenderS3 configures an S3 compatible bucket. Fields left empty fall back to the MINIO_ENDPOINT,
+// MINIO_ACCESS_KEY_ID, MINIO_SECRET_ACCESS_KEY, MINIO_BUCKET, and MINIO_USE_SSL env variables so that credentials
+// do not need to be stored in the config file.
+type RenderS3 struct {
+	Endpoint        string `yaml:"endpoint"`
+	Region          string `yaml:"region"`
+	Bucket          string `yaml:"bucket"`
+	AccessKeyId     string `yaml:"accessKeyId"`
+	SecretAccessKey string `yaml:"secretAccessKey"`
+	UseSSL          *bool  `yaml:"useSSL"`
+	// Public returns plain object URLs for a publicly readable bucket. By default the bucket is assumed to be private
+	// and presigned URLs are returned.
+	Public bool `yaml:"public"`
+	// UrlTtl is how long presigned URLs are valid for as a duration like 24h, at most 168h. Defaults to 24h.
+	UrlTtl string `yaml:"urlTtl"`
+	// ExpireAfter optionally marks renders to expire after a duration like 720h. The objects get an Expires header and
+	// canyon-render and canyon-expires tags that a bucket lifecycle rule can act on.
+	ExpireAfter string `yaml:"expireAfter"`
+}
+
+// Path returns the location of the config file. This is ${HOME}/canyon-config.yaml unless overridden by the
+// CANYON_CONFIG_FILE environment variable.
+func Path() (string, error) {
+	if v := os.Getenv("CANYON_CONFIG_FILE"); v != "" {
+		return v, nil
+	}
+	h, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to identify the users home directory: %w", err)
+	}
+	return filepath.Join(h, "canyon-config.yaml"), nil
+}
+
+// Load reads the config file. A missing config file is not an error and results in an empty config.
+func Load() (*Config, error) {
+	p, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := os.ReadFile(p)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("failed to read the config file: %w", err)
+	}
+	c := new(Config)
+	if err := yaml.Unmarshal(raw, c); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the config file %s: %w", p, err)
+	}
+	return c, nil
+}
@@ -0,0 +1,97 @@
+// Package diagram lays out the node and link graphs and the trees of the render tools and draws them as static SVG,
+// without a browser. Trees use a layered tidy tree layout and networks a layered Sugiyama style layout, both are
+// deterministic so the same input always results in the same diagram.
+package diagram
+
+// Well known node classes, other classes are drawn like ClassOther.
+const (
+	ClassOrg      = "org"
+	ClassApp      = "app"
+	ClassEnvType  = "env_type"
+	ClassEnv      = "env"
+	ClassWorkload = "workload"
+	ClassResource = "resource"
+	ClassOther    = "other"
+)
+
+// Node is a node in a network graph.
+type Node struct {
+	Id    string
+	Class string
+}
+
+// Link is a directed link between two nodes in a network graph.
+type Link struct {
+	Source string
+	Target string
+	Label  string
+}
+
+// Graph is a network of nodes and links.
+type Graph struct {
+	Nodes []Node
+	Links []Link
+}
+
+// TreeNode is a node in a tree.
+type TreeNode struct {
+	Name     string
+	Class    string
+	Children []TreeNode
+}
+
+// Point is a position in the diagram.
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Box is a placed node, X and Y are the top left corner.
+type Box struct {
+	Label  string
+	Class  string
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+}
+
+// Center returns the center of the box.
+func (b Box) Center() Point {
+	return Point{X: b.X + b.Width/2, Y: b.Y + b.Height/2}
+}
+
+// Edge is a placed link drawn as a polyline through the points, from the source to the target.
+type Edge struct {
+	Points []Point
+	Label  string
+}
+
+// Layout is a placed diagram ready to be drawn.
+type Layout struct {
+	Width  float64
+	Height float64
+	Boxes  []Box
+	Edges  []Edge
+}
+
+const (
+	margin      = 20.0
+	boxHeight   = 36.0
+	minBoxWidth = 80.0
+	charWidth   = 7.0
+	boxPadding  = 24.0
+	layerGap    = 64.0
+	siblingGap  = 24.0
+	dummyWidth  = 8.0
+)
+
+// boxWidth estimates the width needed for the label at the font size used in the SVG.
+func boxWidth(label string) float64 {
+	return max(minBoxWidth, float64(len([]rune(label)))*charWidth+boxPadding)
+}
+
+// layerY returns the top of the boxes in the layer.
+func layerY(layer int) float64 {
+	return margin + float64(layer)*(boxHeight+layerGap)
+}
@@ -0,0 +1,112 @@
+package diagram
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayoutTree(t *testing.T) {
+	l := LayoutTree(TreeNode{Name: "org", Class: ClassOrg, Children: []TreeNode{
+		{Name: "app-a", Class: ClassApp, Children: []TreeNode{{Name: "dev", Class: ClassEnv}, {Name: "prod", Class: ClassEnv}}},
+		{Name: "app-b", Class: ClassApp},
+	}})
+	assert.Len(t, l.Boxes, 5)
+	assert.Len(t, l.Edges, 4)
+
+	org, appA, dev, prod, appB := l.Boxes[0], l.Boxes[1], l.Boxes[2], l.Boxes[3], l.Boxes[4]
+	// layers from top to bottom
+	assert.Equal(t, org.Y, margin)
+	assert.Equal(t, appA.Y, appB.Y)
+	assert.Equal(t, dev.Y, prod.Y)
+	assert.Greater(t, appA.Y, org.Y)
+	assert.Greater(t, dev.Y, appA.Y)
+	// parents are centered over their children and siblings do not overlap
+	assert.InDelta(t, (dev.Center().X+prod.Center().X)/2, appA.Center().X, 0.01)
+	assert.InDelta(t, (appA.Center().X+appB.Center().X)/2, org.Center().X, 0.01)
+	assert.LessOrEqual(t, dev.X+dev.Width+siblingGap, prod.X)
+	assert.LessOrEqual(t, prod.X+prod.Width, appB.X)
+	// everything fits in the layout
+	for _, b := range l.Boxes {
+		assert.GreaterOrEqual(t, b.X, margin)
+		assert.LessOrEqual(t, b.X+b.Width, l.Width-margin+0.01)
+		assert.LessOrEqual(t, b.Y+b.Height, l.Height-margin+0.01)
+	}
+	// edges run from the bottom of the parent to the top of the child
+	assert.Equal(t, Point{X: org.Center().X, Y: org.Y + org.Height}, l.Edges[3].Points[0])
+	assert.Equal(t, Point{X: appB.Center().X, Y: appB.Y}, l.Edges[3].Points[3])
+}
+
+func TestLayoutGraph(t *testing.T) {
+	l, err := LayoutGraph(Graph{
+		Nodes: []Node{{Id: "app", Class: ClassApp}, {Id: "db", Class: ClassResource}, {Id: "web", Class: ClassWorkload}, {Id: "dns", Class: ClassResource}},
+		Links: []Link{
+			{Source: "app", Target: "web"},
+			{Source: "web", Target: "db", Label: "uses"},
+			{Source: "app", Target: "db"},
+			{Source: "dns", Target: "app"},
+		},
+	})
+	assert.NoError(t, err)
+	layerOf := func(i int) int {
+		return int((l.Boxes[i].Y - margin) / (boxHeight + layerGap))
+	}
+	// longest path layering: dns -> app -> web -> db
+	assert.Equal(t, []int{1, 3, 2, 0}, []int{layerOf(0), layerOf(1), layerOf(2), layerOf(3)})
+	// the app to db link spans two layers and is routed through a dummy vertex
+	assert.Len(t, l.Edges[2].Points, 4)
+	assert.Equal(t, "uses", l.Edges[1].Label)
+	assert.Equal(t, layerY(3)+boxHeight+margin, l.Height)
+}
+
+func TestLayoutGraph_cycles(t *testing.T) {
+	l, err := LayoutGraph(Graph{
+		Nodes: []Node{{Id: "a"}, {Id: "b"}, {Id: "c"}},
+		Links: []Link{{Source: "a", Target: "b"}, {Source: "b", Target: "c"}, {Source: "c", Target: "a"}, {Source: "b", Target: "b"}},
+	})
+	assert.NoError(t, err)
+	assert.Less(t, l.Boxes[0].Y, l.Boxes[1].Y)
+	assert.Less(t, l.Boxes[1].Y, l.Boxes[2].Y)
+	// the link closing the cycle still points from c to a
+	c2a := l.Edges[2].Points
+	assert.Equal(t, l.Boxes[2].Center().X, c2a[0].X)
+	assert.Equal(t, l.Boxes[0].Center().X, c2a[len(c2a)-1].X)
+	// the self link is a loop on the right of b
+	assert.Equal(t, l.Boxes[1].X+l.Boxes[1].Width, l.Edges[3].Points[0].X)
+}
+
+func TestLayoutGraph_invalid(t *testing.T) {
+	_, err := LayoutGraph(Graph{Nodes: []Node{{Id: "a"}, {Id: "a"}}})
+	assert.EqualError(t, err, "duplicate node id 'a'")
+	_, err = LayoutGraph(Graph{Nodes: []Node{{Id: "a"}}, Links: []Link{{Source: "a", Target: "b"}}})
+	assert.EqualError(t, err, "link 0 references unknown target node 'b'")
+}
+
+func TestLayoutGraph_empty(t *testing.T) {
+	l, err := LayoutGraph(Graph{})
+	assert.NoError(t, err)
+	assert.Equal(t, Layout{Width: 2 * margin, Height: 2 * margin}, l)
+}
+
+func TestSVG(t *testing.T) {
+	l, err := LayoutGraph(Graph{
+		Nodes: []Node{{Id: "<app>", Class: ClassApp}, {Id: "db & cache", Class: "custom"}},
+		Links: []Link{{Source: "<app>", Target: "db & cache", Label: "a \"b\""}},
+	})
+	assert.NoError(t, err)
+	raw := l.SVG()
+	// the document is well formed xml
+	d := xml.NewDecoder(strings.NewReader(string(raw)))
+	for {
+		if _, err := d.Token(); err != nil {
+			assert.Equal(t, "EOF", err.Error())
+			break
+		}
+	}
+	assert.Contains(t, string(raw), "&lt;app&gt;")
+	assert.Contains(t, string(raw), `fill="`+ClassColor(ClassApp)+`"`)
+	assert.Contains(t, string(raw), `fill="`+ClassColor(ClassOther)+`"`)
+	assert.Contains(t, string(raw), "<polyline ")
+}
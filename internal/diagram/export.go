@@ -0,0 +1,189 @@
+package diagram
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Text formats the diagrams can be exported as.
+const (
+	FormatMermaid = "mermaid"
+	FormatDot     = "dot"
+)
+
+// Formats are the supported text formats.
+var Formats = []string{FormatMermaid, FormatDot}
+
+// flatDiagram is a graph or tree with generated ids, which are safe to use in both Mermaid and DOT.
+type flatDiagram struct {
+	labels  []string
+	classes []string
+	edges   []flatEdge
+}
+
+type flatEdge struct {
+	from  int
+	to    int
+	label string
+}
+
+func flattenGraph(g Graph) (flatDiagram, error) {
+	out := flatDiagram{}
+	index := make(map[string]int, len(g.Nodes))
+	for i, n := range g.Nodes {
+		if _, ok := index[n.Id]; ok {
+			return flatDiagram{}, fmt.Errorf("duplicate node id '%s'", n.Id)
+		}
+		index[n.Id] = i
+		out.labels = append(out.labels, n.Id)
+		out.classes = append(out.classes, n.Class)
+	}
+	for i, lk := range g.Links {
+		s, ok := index[lk.Source]
+		if !ok {
+			return flatDiagram{}, fmt.Errorf("link %d references unknown source node '%s'", i, lk.Source)
+		}
+		t, ok := index[lk.Target]
+		if !ok {
+			return flatDiagram{}, fmt.Errorf("link %d references unknown target node '%s'", i, lk.Target)
+		}
+		out.edges = append(out.edges, flatEdge{from: s, to: t, label: lk.Label})
+	}
+	return out, nil
+}
+
+func flattenTree(root TreeNode) flatDiagram {
+	out := flatDiagram{}
+	var visit func(node TreeNode)
+	visit = func(node TreeNode) {
+		i := len(out.labels)
+		out.labels = append(out.labels, node.Name)
+		out.classes = append(out.classes, node.Class)
+		for _, c := range node.Children {
+			out.edges = append(out.edges, flatEdge{from: i, to: len(out.labels)})
+			visit(c)
+		}
+	}
+	visit(root)
+	return out
+}
+
+// knownClass returns the class if it is well known, otherwise ClassOther.
+func knownClass(class string) string {
+	if _, ok := classColors[class]; ok {
+		return class
+	}
+	return ClassOther
+}
+
+// mermaidShapes are the opening and closing brackets of the Mermaid flowchart node shape of each class.
+var mermaidShapes = map[string][2]string{
+	ClassOrg:      {"{{", "}}"},
+	ClassApp:      {"(", ")"},
+	ClassEnvType:  {"[/", "/]"},
+	ClassEnv:      {"([", "])"},
+	ClassWorkload: {"[", "]"},
+	ClassResource: {"[(", ")]"},
+	ClassOther:    {"[", "]"},
+}
+
+// mermaidText quotes the text for a Mermaid label, using entity codes for the characters that would end it.
+func mermaidText(s string) string {
+	s = strings.NewReplacer(`"`, "#quot;", "\n", " ", "\r", "").Replace(s)
+	return `"` + s + `"`
+}
+
+func (f flatDiagram) mermaid() string {
+	b := new(strings.Builder)
+	b.WriteString("flowchart TD\n")
+	for i, label := range f.labels {
+		shape := mermaidShapes[knownClass(f.classes[i])]
+		_, _ = fmt.Fprintf(b, "    n%d%s%s%s\n", i, shape[0], mermaidText(label), shape[1])
+	}
+	for _, e := range f.edges {
+		if e.label != "" {
+			_, _ = fmt.Fprintf(b, "    n%d -->|%s| n%d\n", e.from, mermaidText(e.label), e.to)
+		} else {
+			_, _ = fmt.Fprintf(b, "    n%d --> n%d\n", e.from, e.to)
+		}
+	}
+	used := make(map[string][]string)
+	for i, class := range f.classes {
+		used[knownClass(class)] = append(used[knownClass(class)], fmt.Sprintf("n%d", i))
+	}
+	classes := make([]string, 0, len(used))
+	for class := range used {
+		classes = append(classes, class)
+	}
+	slices.Sort(classes)
+	for _, class := range classes {
+		_, _ = fmt.Fprintf(b, "    classDef %s fill:%s,stroke:%s,color:#ffffff\n", class, ClassColor(class), ClassColor(class))
+		_, _ = fmt.Fprintf(b, "    class %s %s\n", strings.Join(used[class], ","), class)
+	}
+	return b.String()
+}
+
+// dotShapes are the Graphviz node shapes of each class.
+var dotShapes = map[string]string{
+	ClassOrg:      "hexagon",
+	ClassApp:      "box",
+	ClassEnvType:  "parallelogram",
+	ClassEnv:      "ellipse",
+	ClassWorkload: "box",
+	ClassResource: "cylinder",
+	ClassOther:    "box",
+}
+
+// dotText quotes the text as a DOT string.
+func dotText(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", "").Replace(s) + `"`
+}
+
+func (f flatDiagram) dot() string {
+	b := new(strings.Builder)
+	b.WriteString("digraph canyon {\n")
+	b.WriteString("    rankdir=TB;\n")
+	b.WriteString(`    node [style="rounded,filled", fontcolor="#ffffff", fontname="Helvetica"];` + "\n")
+	b.WriteString(`    edge [color="#9ca3af", fontname="Helvetica", fontsize=10];` + "\n")
+	for i, label := range f.labels {
+		class := knownClass(f.classes[i])
+		_, _ = fmt.Fprintf(b, "    n%d [label=%s, shape=%s, fillcolor=%s, color=%s];\n", i, dotText(label), dotShapes[class], dotText(ClassColor(class)), dotText(ClassColor(class)))
+	}
+	for _, e := range f.edges {
+		if e.label != "" {
+			_, _ = fmt.Fprintf(b, "    n%d -> n%d [label=%s];\n", e.from, e.to, dotText(e.label))
+		} else {
+			_, _ = fmt.Fprintf(b, "    n%d -> n%d;\n", e.from, e.to)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func (f flatDiagram) export(format string) (string, error) {
+	switch format {
+	case FormatMermaid:
+		return f.mermaid(), nil
+	case FormatDot:
+		return f.dot(), nil
+	default:
+		return "", fmt.Errorf("unsupported diagram format '%s', expected one of %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+// ExportGraph returns the network as Mermaid flowchart or Graphviz DOT text, with the well known classes mapped to
+// shapes and colors.
+func ExportGraph(g Graph, format string) (string, error) {
+	f, err := flattenGraph(g)
+	if err != nil {
+		return "", err
+	}
+	return f.export(format)
+}
+
+// ExportTree returns the tree as Mermaid flowchart or Graphviz DOT text, with the well known classes mapped to shapes
+// and colors.
+func ExportTree(root TreeNode, format string) (string, error) {
+	return flattenTree(root).export(format)
+}
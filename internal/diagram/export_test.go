@@ -0,0 +1,82 @@
+package diagram
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var exampleGraph = Graph{
+	Nodes: []Node{{Id: "my \"app\"", Class: ClassApp}, {Id: "db", Class: ClassResource}, {Id: "x", Class: "custom"}},
+	Links: []Link{{Source: "my \"app\"", Target: "db", Label: "uses"}, {Source: "db", Target: "x"}},
+}
+
+func TestExportGraph_mermaid(t *testing.T) {
+	out, err := ExportGraph(exampleGraph, FormatMermaid)
+	assert.NoError(t, err)
+	assert.Equal(t, `flowchart TD
+    n0("my #quot;app#quot;")
+    n1[("db")]
+    n2["x"]
+    n0 -->|"uses"| n1
+    n1 --> n2
+    classDef app fill:#2563eb,stroke:#2563eb,color:#ffffff
+    class n0 app
+    classDef other fill:#6b7280,stroke:#6b7280,color:#ffffff
+    class n2 other
+    classDef resource fill:#dc2626,stroke:#dc2626,color:#ffffff
+    class n1 resource
+`, out)
+}
+
+func TestExportGraph_dot(t *testing.T) {
+	out, err := ExportGraph(exampleGraph, FormatDot)
+	assert.NoError(t, err)
+	assert.Equal(t, `digraph canyon {
+    rankdir=TB;
+    node [style="rounded,filled", fontcolor="#ffffff", fontname="Helvetica"];
+    edge [color="#9ca3af", fontname="Helvetica", fontsize=10];
+    n0 [label="my \"app\"", shape=box, fillcolor="#2563eb", color="#2563eb"];
+    n1 [label="db", shape=cylinder, fillcolor="#dc2626", color="#dc2626"];
+    n2 [label="x", shape=box, fillcolor="#6b7280", color="#6b7280"];
+    n0 -> n1 [label="uses"];
+    n1 -> n2;
+}
+`, out)
+}
+
+func TestExportGraph_invalid(t *testing.T) {
+	_, err := ExportGraph(Graph{Links: []Link{{Source: "a", Target: "b"}}}, FormatDot)
+	assert.EqualError(t, err, "link 0 references unknown source node 'a'")
+	_, err = ExportGraph(exampleGraph, "png")
+	assert.EqualError(t, err, "unsupported diagram format 'png', expected one of mermaid, dot")
+}
+
+func TestExportTree(t *testing.T) {
+	root := TreeNode{Name: "org", Class: ClassOrg, Children: []TreeNode{
+		{Name: "app", Class: ClassApp, Children: []TreeNode{{Name: "dev", Class: ClassEnv}}},
+		{Name: "app", Class: ClassApp},
+	}}
+	out, err := ExportTree(root, FormatMermaid)
+	assert.NoError(t, err)
+	assert.Equal(t, `flowchart TD
+    n0{{"org"}}
+    n1("app")
+    n2(["dev"])
+    n3("app")
+    n0 --> n1
+    n1 --> n2
+    n0 --> n3
+    classDef app fill:#2563eb,stroke:#2563eb,color:#ffffff
+    class n1,n3 app
+    classDef env fill:#059669,stroke:#059669,color:#ffffff
+    class n2 env
+    classDef org fill:#7c3aed,stroke:#7c3aed,color:#ffffff
+    class n0 org
+`, out)
+
+	out, err = ExportTree(root, FormatDot)
+	assert.NoError(t, err)
+	assert.Contains(t, out, `n0 [label="org", shape=hexagon, fillcolor="#7c3aed", color="#7c3aed"];`)
+	assert.Contains(t, out, "n1 -> n2;\n")
+}
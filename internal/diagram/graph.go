@@ -0,0 +1,264 @@
+package diagram
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// orderingSweeps is the number of barycenter sweeps used to reduce the link crossings between layers.
+const orderingSweeps = 8
+
+// dagEdge is a link between two vertices in the direction it is layered in.
+type dagEdge struct {
+	from int
+	to   int
+}
+
+// LayoutGraph places the network in layers from top to bottom, Sugiyama style. Cycles are broken by reversing the
+// links that close them, each node is placed in the layer after its deepest predecessor, links that span several
+// layers are routed through dummy vertices, and the order within each layer is swept by barycenter to reduce
+// crossings.
+func LayoutGraph(g Graph) (Layout, error) {
+	index := make(map[string]int, len(g.Nodes))
+	for i, n := range g.Nodes {
+		if n.Id == "" {
+			return Layout{}, fmt.Errorf("node %d has no id", i)
+		} else if _, ok := index[n.Id]; ok {
+			return Layout{}, fmt.Errorf("duplicate node id '%s'", n.Id)
+		}
+		index[n.Id] = i
+	}
+	sources := make([]int, len(g.Links))
+	targets := make([]int, len(g.Links))
+	for i, lk := range g.Links {
+		var ok bool
+		if sources[i], ok = index[lk.Source]; !ok {
+			return Layout{}, fmt.Errorf("link %d references unknown source node '%s'", i, lk.Source)
+		}
+		if targets[i], ok = index[lk.Target]; !ok {
+			return Layout{}, fmt.Errorf("link %d references unknown target node '%s'", i, lk.Target)
+		}
+	}
+
+	reversed := breakCycles(len(g.Nodes), sources, targets)
+	edges := make([]dagEdge, len(g.Links))
+	for i := range g.Links {
+		edges[i] = dagEdge{from: sources[i], to: targets[i]}
+		if reversed[i] {
+			edges[i] = dagEdge{from: targets[i], to: sources[i]}
+		}
+	}
+	layers := assignLayers(len(g.Nodes), edges)
+
+	// Split the links spanning more than one layer into chains through dummy vertices.
+	chains := make([][]int, len(g.Links))
+	for i, e := range edges {
+		if e.from == e.to {
+			continue
+		}
+		chain := []int{e.from}
+		for l := layers[e.from] + 1; l < layers[e.to]; l++ {
+			chain = append(chain, len(layers))
+			layers = append(layers, l)
+		}
+		chains[i] = append(chain, e.to)
+	}
+
+	order := orderLayers(layers, chains)
+
+	// Assign the coordinates, centering each layer on the widest one.
+	widths := make([]float64, len(layers))
+	for v := range layers {
+		widths[v] = dummyWidth
+		if v < len(g.Nodes) {
+			widths[v] = boxWidth(g.Nodes[v].Id)
+		}
+	}
+	layerWidths := make([]float64, len(order))
+	maxWidth := 0.0
+	for l, vs := range order {
+		for i, v := range vs {
+			if i > 0 {
+				layerWidths[l] += siblingGap
+			}
+			layerWidths[l] += widths[v]
+		}
+		maxWidth = max(maxWidth, layerWidths[l])
+	}
+	x := make([]float64, len(layers))
+	for l, vs := range order {
+		left := margin + (maxWidth-layerWidths[l])/2
+		for _, v := range vs {
+			x[v] = left
+			left += widths[v] + siblingGap
+		}
+	}
+
+	out := Layout{Width: maxWidth + 2*margin, Height: 2 * margin}
+	if len(order) > 0 {
+		out.Height = layerY(len(order)-1) + boxHeight + margin
+	}
+	for i, n := range g.Nodes {
+		out.Boxes = append(out.Boxes, Box{Label: n.Id, Class: n.Class, X: x[i], Y: layerY(layers[i]), Width: widths[i], Height: boxHeight})
+	}
+	for i, lk := range g.Links {
+		if chains[i] == nil {
+			out.Edges = append(out.Edges, selfLoop(out.Boxes[sources[i]], lk.Label))
+			// leave room for the loop if it is drawn to the right of the widest layer
+			out.Width = max(out.Width, out.Boxes[sources[i]].X+out.Boxes[sources[i]].Width+siblingGap/2+margin)
+			continue
+		}
+		points := make([]Point, 0, len(chains[i]))
+		for j, v := range chains[i] {
+			cx := x[v] + widths[v]/2
+			switch j {
+			case 0:
+				points = append(points, Point{X: cx, Y: layerY(layers[v]) + boxHeight})
+			case len(chains[i]) - 1:
+				points = append(points, Point{X: cx, Y: layerY(layers[v])})
+			default:
+				points = append(points, Point{X: cx, Y: layerY(layers[v])}, Point{X: cx, Y: layerY(layers[v]) + boxHeight})
+			}
+		}
+		if reversed[i] {
+			slices.Reverse(points)
+		}
+		out.Edges = append(out.Edges, Edge{Points: points, Label: lk.Label})
+	}
+	return out, nil
+}
+
+// breakCycles returns the links to reverse so that the graph has no cycles, found by a depth first search in node
+// order. Self links are left as they are.
+func breakCycles(n int, sources, targets []int) []bool {
+	outgoing := make([][]int, n)
+	for i, s := range sources {
+		outgoing[s] = append(outgoing[s], i)
+	}
+	reversed := make([]bool, len(sources))
+	const (
+		unvisited = iota
+		onStack
+		done
+	)
+	state := make([]int, n)
+	var visit func(v int)
+	visit = func(v int) {
+		state[v] = onStack
+		for _, li := range outgoing[v] {
+			t := targets[li]
+			if t == v {
+				continue
+			} else if state[t] == onStack {
+				reversed[li] = true
+			} else if state[t] == unvisited {
+				visit(t)
+			}
+		}
+		state[v] = done
+	}
+	for v := range n {
+		if state[v] == unvisited {
+			visit(v)
+		}
+	}
+	return reversed
+}
+
+// assignLayers places each vertex in the layer after its deepest predecessor, the longest path layering.
+func assignLayers(n int, edges []dagEdge) []int {
+	outgoing := make([][]int, n)
+	inDegree := make([]int, n)
+	for _, e := range edges {
+		if e.from != e.to {
+			outgoing[e.from] = append(outgoing[e.from], e.to)
+			inDegree[e.to]++
+		}
+	}
+	layers := make([]int, n)
+	ready := make([]int, 0, n)
+	for v := range n {
+		if inDegree[v] == 0 {
+			ready = append(ready, v)
+		}
+	}
+	for len(ready) > 0 {
+		v := ready[0]
+		ready = ready[1:]
+		for _, t := range outgoing[v] {
+			layers[t] = max(layers[t], layers[v]+1)
+			if inDegree[t]--; inDegree[t] == 0 {
+				ready = append(ready, t)
+			}
+		}
+	}
+	return layers
+}
+
+// orderLayers returns the vertices of each layer, ordered to reduce the crossings of the chain segments between
+// adjacent layers by alternating downward and upward barycenter sweeps.
+func orderLayers(layers []int, chains [][]int) [][]int {
+	maxLayer := -1
+	for _, l := range layers {
+		maxLayer = max(maxLayer, l)
+	}
+	order := make([][]int, maxLayer+1)
+	for v, l := range layers {
+		order[l] = append(order[l], v)
+	}
+	position := make([]float64, len(layers))
+	for _, vs := range order {
+		for i, v := range vs {
+			position[v] = float64(i)
+		}
+	}
+	up := make([][]int, len(layers))
+	down := make([][]int, len(layers))
+	for _, chain := range chains {
+		for i := 1; i < len(chain); i++ {
+			up[chain[i]] = append(up[chain[i]], chain[i-1])
+			down[chain[i-1]] = append(down[chain[i-1]], chain[i])
+		}
+	}
+
+	sortLayer := func(vs []int, neighbours [][]int) {
+		keys := make(map[int]float64, len(vs))
+		for _, v := range vs {
+			keys[v] = position[v]
+			if len(neighbours[v]) > 0 {
+				sum := 0.0
+				for _, u := range neighbours[v] {
+					sum += position[u]
+				}
+				keys[v] = sum / float64(len(neighbours[v]))
+			}
+		}
+		sort.SliceStable(vs, func(i, j int) bool {
+			return keys[vs[i]] < keys[vs[j]]
+		})
+		for i, v := range vs {
+			position[v] = float64(i)
+		}
+	}
+	for sweep := range orderingSweeps {
+		if sweep%2 == 0 {
+			for l := 1; l < len(order); l++ {
+				sortLayer(order[l], up)
+			}
+		} else {
+			for l := len(order) - 2; l >= 0; l-- {
+				sortLayer(order[l], down)
+			}
+		}
+	}
+	return order
+}
+
+// selfLoop draws a link from a node to itself as a small loop on the right side of its box.
+func selfLoop(b Box, label string) Edge {
+	right, cy := b.X+b.Width, b.Y+b.Height/2
+	return Edge{Label: label, Points: []Point{
+		{X: right, Y: cy - 8}, {X: right + siblingGap/2, Y: cy - 8}, {X: right + siblingGap/2, Y: cy + 8}, {X: right, Y: cy + 8},
+	}}
+}
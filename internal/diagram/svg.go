@@ -0,0 +1,67 @@
+package diagram
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// classColors are the box fill colors of the well known classes, in line with the HTML renders.
+var classColors = map[string]string{
+	ClassOrg:      "#7c3aed",
+	ClassApp:      "#2563eb",
+	ClassEnvType:  "#0891b2",
+	ClassEnv:      "#059669",
+	ClassWorkload: "#d97706",
+	ClassResource: "#dc2626",
+	ClassOther:    "#6b7280",
+}
+
+// ClassColor returns the fill color for the class.
+func ClassColor(class string) string {
+	if c, ok := classColors[class]; ok {
+		return c
+	}
+	return classColors[ClassOther]
+}
+
+func escape(s string) string {
+	buffer := new(bytes.Buffer)
+	_ = xml.EscapeText(buffer, []byte(s))
+	return buffer.String()
+}
+
+func points(ps []Point) string {
+	parts := make([]string, len(ps))
+	for i, p := range ps {
+		parts[i] = fmt.Sprintf("%.1f,%.1f", p.X, p.Y)
+	}
+	return strings.Join(parts, " ")
+}
+
+// SVG draws the layout as a standalone SVG document.
+func (l Layout) SVG() []byte {
+	buffer := new(bytes.Buffer)
+	_, _ = fmt.Fprintf(buffer, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="Archivo, Helvetica, Arial, sans-serif" font-size="12">`, l.Width, l.Height, l.Width, l.Height)
+	buffer.WriteString(`<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z" fill="#9ca3af"/></marker></defs>`)
+	_, _ = fmt.Fprintf(buffer, `<rect width="%.0f" height="%.0f" fill="#ffffff"/>`, l.Width, l.Height)
+	for _, e := range l.Edges {
+		if len(e.Points) < 2 {
+			continue
+		}
+		_, _ = fmt.Fprintf(buffer, `<polyline points="%s" fill="none" stroke="#9ca3af" stroke-width="1.5" marker-end="url(#arrow)"/>`, points(e.Points))
+		if e.Label != "" {
+			// label the middle segment of the edge
+			a, b := e.Points[(len(e.Points)-1)/2], e.Points[(len(e.Points)-1)/2+1]
+			_, _ = fmt.Fprintf(buffer, `<text x="%.1f" y="%.1f" text-anchor="middle" fill="#4b5563" font-size="10" stroke="#ffffff" stroke-width="3" paint-order="stroke">%s</text>`, (a.X+b.X)/2, (a.Y+b.Y)/2, escape(e.Label))
+		}
+	}
+	for _, b := range l.Boxes {
+		c := b.Center()
+		_, _ = fmt.Fprintf(buffer, `<g><title>%s (%s)</title><rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" rx="6" fill="%s"/><text x="%.1f" y="%.1f" text-anchor="middle" dominant-baseline="central" fill="#ffffff">%s</text></g>`,
+			escape(b.Label), escape(b.Class), b.X, b.Y, b.Width, b.Height, ClassColor(b.Class), c.X, c.Y, escape(b.Label))
+	}
+	buffer.WriteString(`</svg>`)
+	return buffer.Bytes()
+}
@@ -0,0 +1,60 @@
+package diagram
+
+// LayoutTree places the tree top down with each parent centered above its children.
+func LayoutTree(root TreeNode) Layout {
+	l := Layout{}
+	width := subtreeWidth(root)
+	placeSubtree(&l, root, margin, 0, width)
+	l.Width = width + 2*margin
+	for _, b := range l.Boxes {
+		l.Height = max(l.Height, b.Y+b.Height+margin)
+	}
+	return l
+}
+
+// subtreeWidth is the width needed for the node and all of its descendants side by side.
+func subtreeWidth(node TreeNode) float64 {
+	return max(boxWidth(node.Name), childrenWidth(node))
+}
+
+func childrenWidth(node TreeNode) float64 {
+	total := 0.0
+	for i, c := range node.Children {
+		if i > 0 {
+			total += siblingGap
+		}
+		total += subtreeWidth(c)
+	}
+	return total
+}
+
+// placeSubtree places the children of the node side by side in the horizontal span from left with the given width, and
+// the node above them, centered over its first and last child. It returns the index of the box of the node.
+func placeSubtree(l *Layout, node TreeNode, left float64, depth int, width float64) int {
+	w := boxWidth(node.Name)
+	index := len(l.Boxes)
+	l.Boxes = append(l.Boxes, Box{Label: node.Name, Class: node.Class, X: left + (width-w)/2, Y: layerY(depth), Width: w, Height: boxHeight})
+	if len(node.Children) == 0 {
+		return index
+	}
+
+	childLeft := left + (width-childrenWidth(node))/2
+	children := make([]int, len(node.Children))
+	for i, c := range node.Children {
+		cw := subtreeWidth(c)
+		children[i] = placeSubtree(l, c, childLeft, depth+1, cw)
+		childLeft += cw + siblingGap
+	}
+	center := (l.Boxes[children[0]].Center().X + l.Boxes[children[len(children)-1]].Center().X) / 2
+	l.Boxes[index].X = min(max(center-w/2, left), left+width-w)
+
+	parent := l.Boxes[index]
+	for _, ci := range children {
+		child := l.Boxes[ci]
+		from := Point{X: parent.Center().X, Y: parent.Y + parent.Height}
+		to := Point{X: child.Center().X, Y: child.Y}
+		midY := (from.Y + to.Y) / 2
+		l.Edges = append(l.Edges, Edge{Points: []Point{from, {X: from.X, Y: midY}, {X: to.X, Y: midY}, to}})
+	}
+	return index
+}
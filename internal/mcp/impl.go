@@ -13,6 +13,8 @@ import (
 type Impl struct {
 	Instructions string
 	Tools        []Tool
+	// Resources are the resources that can be listed and read, nil if the server has none.
+	Resources *ResourceRegistry
 
 	lock sync.Mutex
 }
@@ -28,6 +30,11 @@ func (m *Impl) GetPrompt(ctx context.Context, request GetPromptRequest) (*GetPro
 }
 
 func (m *Impl) ReadResource(ctx context.Context, request ReadResourceRequest) (*ReadResourceResponse, error) {
+	if m.Resources != nil {
+		if c, ok := m.Resources.Read(request.Uri); ok {
+			return &ReadResourceResponse{Contents: []ResourceContent{c}}, nil
+		}
+	}
 	return nil, rpc.JsonRpcError{Code: -32002, Message: "Unknown resource"}
 }
 
@@ -40,6 +47,9 @@ func (m *Impl) ListPrompts(ctx context.Context, request ListPromptsRequest) (*Li
 }
 
 func (m *Impl) ListResources(ctx context.Context, request ListResourcesRequest) (*ListResourcesResponse, error) {
+	if m.Resources != nil {
+		return &ListResourcesResponse{Resources: m.Resources.List()}, nil
+	}
 	return &ListResourcesResponse{Resources: []Resource{}}, nil
 }
 
@@ -64,6 +74,7 @@ func (m *Impl) ListTools(ctx context.Context, request ListToolsRequest) (*ListTo
 			Name:        tool.Name,
 			Description: tool.Description,
 			InputSchema: tool.InputSchema,
+			Annotations: tool.Annotations,
 		}
 	}
 	return &ListToolsResponse{Tools: resp}, nil
@@ -2,6 +2,7 @@ package mcp
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -64,6 +65,17 @@ type ToolResponse struct {
 	Name        string                 `json:"name"`
 	Description string                 `json:"description"`
 	InputSchema map[string]interface{} `json:"inputSchema"`
+	Annotations *ToolAnnotations       `json:"annotations,omitempty"`
+}
+
+// ToolAnnotations are hints to the client about the behavior of a tool. A client may use these to ask the user for
+// confirmation before calling a destructive tool.
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    *bool  `json:"readOnlyHint,omitempty"`
+	DestructiveHint *bool  `json:"destructiveHint,omitempty"`
+	IdempotentHint  *bool  `json:"idempotentHint,omitempty"`
+	OpenWorldHint   *bool  `json:"openWorldHint,omitempty"`
 }
 
 type CallToolRequest struct {
@@ -102,6 +114,16 @@ func NewTextToolResponseContentWithAudience(text string, aud string) CallToolRes
 	return CallToolResponseContent{TextContent: &TextContent{Text: text, Annotations: &Annotations{Audience: []string{aud}}}}
 }
 
+// NewEmbeddedTextResourceToolResponseContent embeds a text resource, such as rendered HTML, in the tool response.
+func NewEmbeddedTextResourceToolResponseContent(uri string, mimeType string, text string) CallToolResponseContent {
+	return CallToolResponseContent{EmbeddedResource: &EmbeddedResource{Resource: NewTextResourceContent(uri, mimeType, text)}}
+}
+
+// NewImageToolResponseContent returns the image as base64 encoded image content.
+func NewImageToolResponseContent(mimeType string, data []byte) CallToolResponseContent {
+	return CallToolResponseContent{ImageContent: &ImageContent{MimeType: mimeType, Data: base64.StdEncoding.EncodeToString(data)}}
+}
+
 type TextContentType struct {
 }
 
@@ -261,6 +283,10 @@ type BlobResourceContent struct {
 	MimeType *string `json:"mimeType,omitempty"`
 }
 
+func NewTextResourceContent(uri string, mimeType string, text string) ResourceContent {
+	return ResourceContent{TextResourceContent: &TextResourceContent{Uri: uri, Text: text, MimeType: &mimeType}}
+}
+
 type ResourceContent struct {
 	*TextResourceContent
 	*BlobResourceContent
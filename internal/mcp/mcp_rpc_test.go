@@ -1,6 +1,7 @@
 package mcp
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 
@@ -20,3 +21,15 @@ func TestToolContentEncoding(t *testing.T) {
 	raw, _ = json.Marshal(o)
 	assert.Equal(t, "{\"type\":\"text\",\"text\":\"something\",\"annotations\":{\"audience\":[\"aud\"]}}", string(raw))
 }
+
+func TestListToolsAnnotations(t *testing.T) {
+	yes, no := true, false
+	impl := &Impl{Tools: []Tool{
+		{Name: "plain"},
+		{Name: "delete", Annotations: &ToolAnnotations{Title: "Delete", ReadOnlyHint: &no, DestructiveHint: &yes}},
+	}}
+	resp, err := impl.ListTools(context.Background(), ListToolsRequest{})
+	assert.NoError(t, err)
+	raw, _ := json.Marshal(resp)
+	assert.Equal(t, `{"tools":[{"name":"plain","description":"","inputSchema":null},{"name":"delete","description":"","inputSchema":null,"annotations":{"title":"Delete","readOnlyHint":false,"destructiveHint":true}}]}`, string(raw))
+}
@@ -0,0 +1,53 @@
+package mcp
+
+import (
+	"slices"
+	"sync"
+)
+
+// DefaultMaxResources is the number of resources a registry keeps before evicting the oldest.
+const DefaultMaxResources = 100
+
+// ResourceRegistry holds the resources produced at runtime, such as the renders of tools, so that clients can list
+// them and read them back with resources/read. Only the most recent MaxResources are kept.
+type ResourceRegistry struct {
+	MaxResources int
+
+	lock      sync.RWMutex
+	resources []Resource
+	contents  map[string]ResourceContent
+}
+
+func NewResourceRegistry() *ResourceRegistry {
+	return &ResourceRegistry{MaxResources: DefaultMaxResources, contents: make(map[string]ResourceContent)}
+}
+
+// Add registers the resource, replacing any previous resource with the same uri.
+func (r *ResourceRegistry) Add(resource Resource, content ResourceContent) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.resources = slices.DeleteFunc(r.resources, func(existing Resource) bool {
+		return existing.Uri == resource.Uri
+	})
+	r.resources = append(r.resources, resource)
+	r.contents[resource.Uri] = content
+	for r.MaxResources > 0 && len(r.resources) > r.MaxResources {
+		delete(r.contents, r.resources[0].Uri)
+		r.resources = r.resources[1:]
+	}
+}
+
+// List returns the registered resources, oldest first.
+func (r *ResourceRegistry) List() []Resource {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return slices.Clone(r.resources)
+}
+
+// Read returns the content of the resource with the uri.
+func (r *ResourceRegistry) Read(uri string) (ResourceContent, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	c, ok := r.contents[uri]
+	return c, ok
+}
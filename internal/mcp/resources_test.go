@@ -0,0 +1,41 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceRegistry(t *testing.T) {
+	r := NewResourceRegistry()
+	r.MaxResources = 2
+	r.Add(Resource{Uri: "canyon://a", Name: "a"}, NewTextResourceContent("canyon://a", "text/plain", "a1"))
+	r.Add(Resource{Uri: "canyon://b", Name: "b"}, NewTextResourceContent("canyon://b", "text/plain", "b"))
+	r.Add(Resource{Uri: "canyon://a", Name: "a"}, NewTextResourceContent("canyon://a", "text/plain", "a2"))
+	assert.Equal(t, []Resource{{Uri: "canyon://b", Name: "b"}, {Uri: "canyon://a", Name: "a"}}, r.List())
+	c, ok := r.Read("canyon://a")
+	assert.True(t, ok)
+	assert.Equal(t, "a2", c.TextResourceContent.Text)
+
+	// the oldest resource is evicted
+	r.Add(Resource{Uri: "canyon://c", Name: "c"}, NewTextResourceContent("canyon://c", "text/plain", "c"))
+	_, ok = r.Read("canyon://b")
+	assert.False(t, ok)
+	assert.Len(t, r.List(), 2)
+}
+
+func TestImplReadResource(t *testing.T) {
+	m := &Impl{}
+	_, err := m.ReadResource(context.Background(), ReadResourceRequest{Uri: "canyon://a"})
+	assert.EqualError(t, err, "json rpc error: -32002: Unknown resource")
+
+	m.Resources = NewResourceRegistry()
+	m.Resources.Add(Resource{Uri: "canyon://a", Name: "a"}, NewTextResourceContent("canyon://a", "text/html", "<p>hi</p>"))
+	list, err := m.ListResources(context.Background(), ListResourcesRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, list.Resources, 1)
+	resp, err := m.ReadResource(context.Background(), ReadResourceRequest{Uri: "canyon://a"})
+	assert.NoError(t, err)
+	assert.Equal(t, []ResourceContent{NewTextResourceContent("canyon://a", "text/html", "<p>hi</p>")}, resp.Contents)
+}
@@ -6,5 +6,6 @@ type Tool struct {
 	Name        string
 	Description string
 	InputSchema map[string]interface{}
+	Annotations *ToolAnnotations
 	Callable    func(ctx context.Context, arguments map[string]interface{}) ([]CallToolResponseContent, error)
 }
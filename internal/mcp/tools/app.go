@@ -2,7 +2,13 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/humanitec/humanitec-go-autogen/client"
 
@@ -42,3 +48,94 @@ func NewGetHumanitecDeploymentSets() mcp.Tool {
 		},
 	}
 }
+
+// getDeploymentSet fetches and decodes the contents of a single deployment set.
+func getDeploymentSet(ctx context.Context, hc *humanitec.WrappedHumanitecClientImpl, orgId, appId, setId string) (*client.SetResponse, error) {
+	r, err := humanitec.CheckResponse(func() (*client.GetSetResponse, error) {
+		return hc.GetSetWithResponse(ctx, orgId, appId, setId, &client.GetSetParams{})
+	}).AndStatusCodeEq(http.StatusOK).RespAndError()
+	if err != nil {
+		return nil, err
+	}
+	var out client.SetResponse
+	if err := json.Unmarshal(r.Body, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode set %s: %w", setId, err)
+	}
+	return &out, nil
+}
+
+// getDeployedSetId returns the id of the deployment set deployed in the latest deployment of the environment.
+func getDeployedSetId(ctx context.Context, hc *humanitec.WrappedHumanitecClientImpl, orgId, appId, envId string) (string, error) {
+	r, err := humanitec.CheckResponse(func() (*client.GetEnvironmentResponse, error) {
+		return hc.GetEnvironmentWithResponse(ctx, orgId, appId, envId)
+	}).AndStatusCodeEq(http.StatusOK).RespAndError()
+	if err != nil {
+		return "", err
+	}
+	if r.JSON200.LastDeploy == nil {
+		return "", fmt.Errorf("environment '%s' of application '%s' has never been deployed", envId, appId)
+	}
+	return r.JSON200.LastDeploy.SetId, nil
+}
+
+// deployedSet is the deployment set deployed in the latest deployment of an environment.
+type deployedSet struct {
+	AppId   string
+	EnvId   string
+	EnvType string
+	Set     *client.SetResponse
+}
+
+// fetchDeployedSets concurrently fetches the deployment set of every deployed environment in the apps. Sets shared
+// by several environments of the same application are only fetched once. Environments that fail to fetch are returned
+// as a joined error alongside the sets that could be fetched.
+func fetchDeployedSets(ctx context.Context, hc *humanitec.WrappedHumanitecClientImpl, orgId string, apps map[string]appstate) ([]deployedSet, error) {
+	type setKey struct{ appId, setId string }
+	sets := new(sync.Map)
+	{
+		wg := new(sync.WaitGroup)
+		sem := make(chan struct{}, 10)
+		seen := make(map[setKey]bool)
+		for appId, app := range apps {
+			for _, env := range app.Environments {
+				k := setKey{appId, env.LastDeploymentSet}
+				if env.LastDeploymentSet == "" || seen[k] {
+					continue
+				}
+				seen[k] = true
+				wg.Add(1)
+				sem <- struct{}{}
+				go func() {
+					defer wg.Done()
+					defer func() { <-sem }()
+					if s, err := getDeploymentSet(ctx, hc, orgId, k.appId, k.setId); err != nil {
+						sets.Store(k, err)
+					} else {
+						sets.Store(k, s)
+					}
+				}()
+			}
+		}
+		wg.Wait()
+	}
+
+	var err error
+	out := make([]deployedSet, 0)
+	for appId, app := range apps {
+		for envId, env := range app.Environments {
+			if env.LastDeploymentSet == "" {
+				continue
+			}
+			v, _ := sets.Load(setKey{appId, env.LastDeploymentSet})
+			if e, ok := v.(error); ok {
+				err = errors.Join(err, fmt.Errorf("failed to fetch set of env '%s' in app '%s': %w", envId, appId, e))
+			} else if s, ok := v.(*client.SetResponse); ok {
+				out = append(out, deployedSet{AppId: appId, EnvId: envId, EnvType: env.Type, Set: s})
+			}
+		}
+	}
+	slices.SortFunc(out, func(a, b deployedSet) int {
+		return strings.Compare(a.AppId+"/"+a.EnvId, b.AppId+"/"+b.EnvId)
+	})
+	return out, err
+}
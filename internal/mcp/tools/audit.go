@@ -0,0 +1,325 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/humanitec/humanitec-go-autogen/client"
+
+	"github.com/humanitec/canyon-cli/internal/clients/humanitec"
+	"github.com/humanitec/canyon-cli/internal/mcp"
+	"github.com/humanitec/canyon-cli/internal/ref"
+)
+
+const (
+	auditEventDeployment = "deployment"
+	auditEventApiRequest = "api_request"
+	auditLogMaxPages     = 20
+)
+
+type auditEvent struct {
+	At      time.Time `json:"at"`
+	Kind    string    `json:"kind"`
+	UserId  string    `json:"userId"`
+	AppId   string    `json:"appId,omitempty"`
+	EnvId   string    `json:"envId,omitempty"`
+	Summary string    `json:"summary"`
+	Status  string    `json:"status"`
+}
+
+type auditFilter struct {
+	AppId  string
+	EnvId  string
+	UserId string
+	From   time.Time
+	To     time.Time
+}
+
+// parseAuditFilter parses the filter arguments of the audit timeline tool. The time range ends now and starts 7 days
+// before its end unless set.
+func parseAuditFilter(m map[string]interface{}, now time.Time) (auditFilter, error) {
+	filter := auditFilter{To: now}
+	filter.AppId, _ = m["app_id"].(string)
+	filter.EnvId, _ = m["env_id"].(string)
+	filter.UserId, _ = m["user_id"].(string)
+	for _, k := range []string{"from", "to"} {
+		if v, ok := m[k].(string); ok && v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s time: %w", k, err)
+			} else if k == "from" {
+				filter.From = parsed
+			} else {
+				filter.To = parsed
+			}
+		}
+	}
+	if filter.From.IsZero() {
+		filter.From = filter.To.AddDate(0, 0, -7)
+	} else if filter.From.After(filter.To) {
+		return filter, fmt.Errorf("the from time %s is after the to time %s", filter.From.Format(time.RFC3339), filter.To.Format(time.RFC3339))
+	}
+	return filter, nil
+}
+
+func (f auditFilter) matches(e auditEvent) bool {
+	return (f.AppId == "" || e.AppId == f.AppId) &&
+		(f.EnvId == "" || e.EnvId == f.EnvId) &&
+		(f.UserId == "" || e.UserId == f.UserId) &&
+		!e.At.Before(f.From) && !e.At.After(f.To)
+}
+
+var auditPathPattern = regexp.MustCompile(`^/orgs/[^/]+(?:/apps/([^/]+)(?:/envs/([^/]+))?)?`)
+
+// parseAuditPath extracts the app and environment from the request path of an audit log entry.
+func parseAuditPath(p string) (appId, envId string) {
+	if m := auditPathPattern.FindStringSubmatch(p); m != nil {
+		return m[1], m[2]
+	}
+	return "", ""
+}
+
+func newAuditEventFromLogEntry(e client.AuditLogEntry) auditEvent {
+	appId, envId := parseAuditPath(e.RequestPath)
+	return auditEvent{
+		At:      e.At,
+		Kind:    auditEventApiRequest,
+		UserId:  e.UserId,
+		AppId:   appId,
+		EnvId:   envId,
+		Summary: e.RequestMethod + " " + e.RequestPath,
+		Status:  strconv.Itoa(e.ResponseStatus),
+	}
+}
+
+func newAuditEventFromDeployment(appId string, d client.DeploymentResponse) auditEvent {
+	summary := fmt.Sprintf("deployment %s of set %s", d.Id, d.SetId)
+	if d.Comment != "" {
+		summary += ": " + d.Comment
+	}
+	return auditEvent{
+		At:      d.CreatedAt,
+		Kind:    auditEventDeployment,
+		UserId:  d.CreatedBy,
+		AppId:   appId,
+		EnvId:   d.EnvId,
+		Summary: summary,
+		Status:  d.Status,
+	}
+}
+
+var nextLinkPattern = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="?next"?`)
+
+// nextPageToken returns the page token of the next page from the Link header of a paginated response.
+func nextPageToken(h http.Header) string {
+	for _, l := range h.Values("Link") {
+		if m := nextLinkPattern.FindStringSubmatch(l); m != nil {
+			if u, err := url.Parse(m[1]); err == nil {
+				return u.Query().Get("page")
+			}
+		}
+	}
+	return ""
+}
+
+// listAuditLogEvents pages through the audit log of the org within the time range. Read only requests are skipped
+// since they do not change anything.
+func listAuditLogEvents(ctx context.Context, hc *humanitec.WrappedHumanitecClientImpl, orgId string, from, to time.Time) ([]auditEvent, error) {
+	out := make([]auditEvent, 0)
+	params := &client.ListAuditLogEntriesParams{PerPage: ref.Ref(100), From: &from, To: &to}
+	for i := 0; i < auditLogMaxPages; i++ {
+		r, err := humanitec.CheckResponse(func() (*client.ListAuditLogEntriesResponse, error) {
+			return hc.ListAuditLogEntriesWithResponse(ctx, orgId, params)
+		}).AndStatusCodeEq(http.StatusOK).RespAndError()
+		if err != nil {
+			return out, err
+		}
+		for _, e := range *r.JSON200 {
+			if e.RequestMethod != http.MethodGet && e.RequestMethod != http.MethodHead {
+				out = append(out, newAuditEventFromLogEntry(e))
+			}
+		}
+		next := nextPageToken(r.HTTPResponse.Header)
+		if next == "" {
+			break
+		}
+		params.Page = &next
+	}
+	return out, nil
+}
+
+// listDeploymentEvents fetches the deployments of every environment in the apps concurrently.
+func listDeploymentEvents(ctx context.Context, hc *humanitec.WrappedHumanitecClientImpl, orgId string, apps map[string]appstate) ([]auditEvent, error) {
+	events := new(sync.Map)
+	wg := new(sync.WaitGroup)
+	sem := make(chan struct{}, 10)
+	for appId, app := range apps {
+		for envId := range app.Environments {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				if r, err := humanitec.CheckResponse(func() (*client.ListDeploymentsResponse, error) {
+					return hc.ListDeploymentsWithResponse(ctx, orgId, appId, envId, &client.ListDeploymentsParams{})
+				}).AndStatusCodeEq(http.StatusOK).RespAndError(); err != nil {
+					events.Store(appId+"/"+envId, err)
+				} else {
+					out := make([]auditEvent, 0, len(*r.JSON200))
+					for _, d := range *r.JSON200 {
+						out = append(out, newAuditEventFromDeployment(appId, d))
+					}
+					events.Store(appId+"/"+envId, out)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	var err error
+	out := make([]auditEvent, 0)
+	events.Range(func(key, value any) bool {
+		if e, ok := value.(error); ok {
+			err = errors.Join(err, fmt.Errorf("failed to list deployments of '%s': %w", key, e))
+		} else if evs, ok := value.([]auditEvent); ok {
+			out = append(out, evs...)
+		}
+		return true
+	})
+	return out, err
+}
+
+// buildAuditTimeline filters the events and sorts them chronologically, keeping only the most recent limit events.
+func buildAuditTimeline(events []auditEvent, filter auditFilter, limit int) []auditEvent {
+	out := make([]auditEvent, 0, len(events))
+	for _, e := range events {
+		if filter.matches(e) {
+			out = append(out, e)
+		}
+	}
+	slices.SortStableFunc(out, func(a, b auditEvent) int {
+		return a.At.Compare(b.At)
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[len(out)-limit:]
+	}
+	return out
+}
+
+func auditTimelineToCsv(events []auditEvent) string {
+	buff := new(bytes.Buffer)
+	w := csv.NewWriter(buff)
+	_ = w.Write([]string{"at", "kind", "user", "app", "env", "summary", "status"})
+	for _, e := range events {
+		_ = w.Write([]string{e.At.Format(time.RFC3339), e.Kind, e.UserId, e.AppId, e.EnvId, e.Summary, e.Status})
+	}
+	w.Flush()
+	return buff.String()
+}
+
+func NewGetAuditTimeline() mcp.Tool {
+	return mcp.Tool{
+		Name: "get_humanitec_audit_timeline",
+		Description: `This tool answers "who deployed or changed what, where, and when" in a Humanitec Organization. It combines the deployment history of the Environments with the Organization audit log of changing API requests into a single chronological timeline, oldest first.
+The timeline can be filtered by app_id, env_id, user_id, and a from/to time range in RFC3339 format, which defaults to the last 7 days. Only the most recent limit events are returned.
+Reading the audit log requires the administrator role in the Organization; if it is not accessible only deployments are returned.
+Set render_csv to also render the timeline as an HTML table with the csv table renderer and return its link.`,
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"org_id":            map[string]interface{}{"type": "string", "description": "The Humanitec Organization (org) ID to work with."},
+				"app_id":            map[string]interface{}{"type": "string", "description": "Optional Humanitec Application (app) ID to filter for"},
+				"env_id":            map[string]interface{}{"type": "string", "description": "Optional Humanitec Environment (env) ID to filter for"},
+				"user_id":           map[string]interface{}{"type": "string", "description": "Optional user ID to filter for"},
+				"from":              map[string]interface{}{"type": "string", "description": "Optional start of the time range in RFC3339 format"},
+				"to":                map[string]interface{}{"type": "string", "description": "Optional end of the time range in RFC3339 format"},
+				"include_audit_log": map[string]interface{}{"type": "boolean", "description": "Whether to include the audit log of API requests, defaults to true"},
+				"limit":             map[string]interface{}{"type": "integer", "description": "The maximum number of events to return, defaults to 200", "minimum": 1},
+				"render_csv":        map[string]interface{}{"type": "boolean", "description": "Whether to render the timeline as an HTML table and return the link"},
+			},
+			"required":             []string{"org_id"},
+			"additionalProperties": false,
+		},
+		Annotations: &mcp.ToolAnnotations{Title: "Get audit timeline", ReadOnlyHint: ref.Ref(true)},
+		Callable: func(ctx context.Context, m map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+			orgId, _ := m["org_id"].(string)
+			filter, err := parseAuditFilter(m, time.Now())
+			if err != nil {
+				return nil, err
+			}
+			includeAuditLog := true
+			if v, ok := m["include_audit_log"].(bool); ok {
+				includeAuditLog = v
+			}
+			limit := 200
+			if v, ok := m["limit"].(float64); ok && v >= 1 {
+				limit = int(v)
+			}
+			renderCsv, _ := m["render_csv"].(bool)
+
+			hc, err := humanitec.NewHumanitecClientWithCurrentToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+			var appIdPattern *regexp.Regexp
+			if filter.AppId != "" {
+				appIdPattern = regexp.MustCompile("^" + regexp.QuoteMeta(filter.AppId) + "$")
+			}
+			apps, appErrors, err := listAppsAndEnvs(ctx, hc, orgId, appIdPattern, "")
+			if err != nil {
+				return nil, err
+			}
+			if filter.EnvId != "" {
+				for appId, app := range apps {
+					if env, ok := app.Environments[filter.EnvId]; ok {
+						app.Environments = map[string]envstate{filter.EnvId: env}
+					} else {
+						app.Environments = nil
+					}
+					apps[appId] = app
+				}
+			}
+
+			out := appErrorsResponse(appErrors)
+			events, err := listDeploymentEvents(ctx, hc, orgId, apps)
+			if err != nil {
+				out = append(out, mcp.NewTextToolResponseContent("Some deployment histories could not be fetched: %v", err.Error()))
+			}
+			if includeAuditLog {
+				auditEvents, err := listAuditLogEvents(ctx, hc, orgId, filter.From, filter.To)
+				if err != nil {
+					out = append(out, mcp.NewTextToolResponseContent("The audit log could not be fully fetched: %v", err.Error()))
+				}
+				events = append(events, auditEvents...)
+			}
+
+			timeline := buildAuditTimeline(events, filter, limit)
+			lines := make([]string, len(timeline))
+			for i, e := range timeline {
+				lines[i] = fmt.Sprintf("%s %s %s/%s %s (%s)", e.At.Format(time.RFC3339), ref.Coalesce(e.UserId, "unknown"), ref.Coalesce(e.AppId, "-"), ref.Coalesce(e.EnvId, "-"), e.Summary, e.Status)
+			}
+			out = append([]mcp.CallToolResponseContent{
+				mcp.NewTextToolResponseContent("The timeline from %s to %s, oldest first:\n%s", filter.From.Format(time.RFC3339), filter.To.Format(time.RFC3339), strings.Join(lines, "\n")),
+			}, out...)
+			if renderCsv {
+				if r, err := renderCsvAsTable(ctx, auditTimelineToCsv(timeline), true, ""); err != nil {
+					out = append(out, mcp.NewTextToolResponseContent("Failed to render the timeline as a table: %v", err.Error()))
+				} else {
+					out = append(out, mcp.NewTextToolResponseContent("The timeline was rendered as a table: %s", r.Url))
+				}
+			}
+			return out, nil
+		},
+	}
+}
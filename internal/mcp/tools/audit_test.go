@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAuditPath(t *testing.T) {
+	for p, expected := range map[string][2]string{
+		"/orgs/o":                              {"", ""},
+		"/orgs/o/apps/shop":                    {"shop", ""},
+		"/orgs/o/apps/shop/envs/prod/values/X": {"shop", "prod"},
+		"/orgs/o/resources/defs/postgres":      {"", ""},
+	} {
+		appId, envId := parseAuditPath(p)
+		assert.Equal(t, expected, [2]string{appId, envId}, p)
+	}
+}
+
+func TestNextPageToken(t *testing.T) {
+	h := http.Header{}
+	h.Add("Link", `<https://api.humanitec.io/orgs/o/audit-logs?page=abc&per_page=100>; rel="next"`)
+	assert.Equal(t, "abc", nextPageToken(h))
+	assert.Equal(t, "", nextPageToken(http.Header{}))
+}
+
+func TestBuildAuditTimeline(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	events := []auditEvent{
+		{At: now.Add(-time.Hour), Kind: auditEventDeployment, UserId: "u1", AppId: "shop", EnvId: "prod"},
+		{At: now.Add(-3 * time.Hour), Kind: auditEventApiRequest, UserId: "u1", AppId: "shop", EnvId: "prod"},
+		{At: now.Add(-2 * time.Hour), Kind: auditEventDeployment, UserId: "u2", AppId: "shop", EnvId: "prod"},
+		{At: now.AddDate(0, 0, -30), Kind: auditEventDeployment, UserId: "u1", AppId: "shop", EnvId: "prod"},
+		{At: now.Add(-time.Hour), Kind: auditEventDeployment, UserId: "u1", AppId: "blog", EnvId: "prod"},
+	}
+	timeline := buildAuditTimeline(events, auditFilter{AppId: "shop", UserId: "u1", From: now.AddDate(0, 0, -7), To: now}, 10)
+	assert.Equal(t, []auditEvent{events[1], events[0]}, timeline)
+	assert.Equal(t, []auditEvent{events[0]}, buildAuditTimeline(events, auditFilter{AppId: "shop", UserId: "u1", From: now.AddDate(0, 0, -7), To: now}, 1))
+}
+
+func TestParseAuditFilter(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	f, err := parseAuditFilter(map[string]interface{}{"app_id": "shop", "user_id": "u1"}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, auditFilter{AppId: "shop", UserId: "u1", From: now.AddDate(0, 0, -7), To: now}, f)
+
+	f, err = parseAuditFilter(map[string]interface{}{"to": "2024-01-05T00:00:00Z"}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 12, 29, 0, 0, 0, 0, time.UTC), f.From)
+
+	f, err = parseAuditFilter(map[string]interface{}{"from": "2024-01-01T00:00:00Z", "to": "2024-01-05T00:00:00Z"}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), f.From)
+	assert.Equal(t, time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC), f.To)
+
+	_, err = parseAuditFilter(map[string]interface{}{"from": "2024-01-05T00:00:00Z", "to": "2024-01-01T00:00:00Z"}, now)
+	assert.EqualError(t, err, "the from time 2024-01-05T00:00:00Z is after the to time 2024-01-01T00:00:00Z")
+	_, err = parseAuditFilter(map[string]interface{}{"from": "2024-02-01T00:00:00Z"}, now)
+	assert.EqualError(t, err, "the from time 2024-02-01T00:00:00Z is after the to time 2024-01-10T12:00:00Z")
+	_, err = parseAuditFilter(map[string]interface{}{"to": "yesterday"}, now)
+	assert.ErrorContains(t, err, "invalid to time")
+}
@@ -0,0 +1,234 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/humanitec/humanitec-go-autogen/client"
+
+	"github.com/humanitec/canyon-cli/internal"
+	"github.com/humanitec/canyon-cli/internal/clients/humanitec"
+	"github.com/humanitec/canyon-cli/internal/mcp"
+)
+
+const (
+	deploymentStatusSucceeded  = "succeeded"
+	deploymentStatusFailed     = "failed"
+	deploymentStatusInProgress = "in progress"
+	deploymentStatusPending    = "pending"
+)
+
+type deploymentSummary struct {
+	Id              string    `json:"id"`
+	SetId           string    `json:"setId"`
+	Status          string    `json:"status"`
+	Comment         string    `json:"comment,omitempty"`
+	CreatedBy       string    `json:"createdBy"`
+	CreatedAt       time.Time `json:"createdAt"`
+	StatusChangedAt time.Time `json:"statusChangedAt"`
+}
+
+func newDeploymentSummary(d client.DeploymentResponse) deploymentSummary {
+	return deploymentSummary{
+		Id:              d.Id,
+		SetId:           d.SetId,
+		Status:          d.Status,
+		Comment:         d.Comment,
+		CreatedBy:       d.CreatedBy,
+		CreatedAt:       d.CreatedAt,
+		StatusChangedAt: d.StatusChangedAt,
+	}
+}
+
+type deploymentError struct {
+	Code      string `json:"code,omitempty"`
+	ErrorType string `json:"errorType,omitempty"`
+	Summary   string `json:"summary,omitempty"`
+	Message   string `json:"message"`
+}
+
+type deploymentDiagnosis struct {
+	Deployment     deploymentSummary                       `json:"deployment"`
+	ErrorsByScope  map[string]map[string][]deploymentError `json:"errorsByScope,omitempty"`
+	Timeline       []deploymentSummary                     `json:"timeline"`
+	LastSucceeded  *deploymentSummary                      `json:"lastSucceededDeployment,omitempty"`
+	FailedInWindow int                                     `json:"failedDeploymentsInTimeline"`
+	Diagnosis      []string                                `json:"diagnosis"`
+}
+
+// diagnoseDeployment condenses a deployment, its errors, and the recent history of the environment into a set of
+// short findings. The timeline must be sorted with the most recent deployment first.
+func diagnoseDeployment(deployment client.DeploymentResponse, errs []client.DeploymentErrorResponse, timeline []client.DeploymentResponse) deploymentDiagnosis {
+	out := deploymentDiagnosis{
+		Deployment: newDeploymentSummary(deployment),
+		Timeline:   make([]deploymentSummary, 0, len(timeline)),
+		Diagnosis:  make([]string, 0),
+	}
+
+	for _, d := range timeline {
+		out.Timeline = append(out.Timeline, newDeploymentSummary(d))
+		if d.Status == deploymentStatusFailed {
+			out.FailedInWindow++
+		}
+		if out.LastSucceeded == nil && d.Status == deploymentStatusSucceeded && d.Id != deployment.Id {
+			s := newDeploymentSummary(d)
+			out.LastSucceeded = &s
+		}
+	}
+
+	if len(errs) > 0 {
+		out.ErrorsByScope = make(map[string]map[string][]deploymentError)
+		for _, e := range errs {
+			scope, object := e.Scope, e.ObjectId
+			if scope == "" {
+				scope = "deployment"
+			}
+			if object == "" {
+				object = "-"
+			}
+			if out.ErrorsByScope[scope] == nil {
+				out.ErrorsByScope[scope] = make(map[string][]deploymentError)
+			}
+			out.ErrorsByScope[scope][object] = append(out.ErrorsByScope[scope][object], deploymentError{
+				Code:      e.Code,
+				ErrorType: e.ErrorType,
+				Summary:   e.Summary,
+				Message:   e.Message,
+			})
+		}
+	}
+
+	switch deployment.Status {
+	case deploymentStatusSucceeded:
+		out.Diagnosis = append(out.Diagnosis, fmt.Sprintf("Deployment %s succeeded at %s.", deployment.Id, deployment.StatusChangedAt.Format(time.RFC3339)))
+	case deploymentStatusInProgress, deploymentStatusPending:
+		out.Diagnosis = append(out.Diagnosis, fmt.Sprintf("Deployment %s is still %s since %s, the result is not known yet.", deployment.Id, deployment.Status, deployment.CreatedAt.Format(time.RFC3339)))
+	case deploymentStatusFailed:
+		out.Diagnosis = append(out.Diagnosis, fmt.Sprintf("Deployment %s failed at %s.", deployment.Id, deployment.StatusChangedAt.Format(time.RFC3339)))
+	default:
+		out.Diagnosis = append(out.Diagnosis, fmt.Sprintf("Deployment %s has status '%s'.", deployment.Id, deployment.Status))
+	}
+
+	scopes := make([]string, 0, len(out.ErrorsByScope))
+	for scope := range out.ErrorsByScope {
+		scopes = append(scopes, scope)
+	}
+	slices.Sort(scopes)
+	for _, scope := range scopes {
+		objects := make([]string, 0, len(out.ErrorsByScope[scope]))
+		for object := range out.ErrorsByScope[scope] {
+			objects = append(objects, object)
+		}
+		slices.Sort(objects)
+		for _, object := range objects {
+			first := out.ErrorsByScope[scope][object][0]
+			reason := first.Summary
+			if reason == "" {
+				reason = first.Message
+			}
+			out.Diagnosis = append(out.Diagnosis, fmt.Sprintf("%s '%s' reported %d error(s), first: %s", scope, object, len(out.ErrorsByScope[scope][object]), reason))
+		}
+	}
+
+	if deployment.Status == deploymentStatusFailed {
+		if out.LastSucceeded != nil {
+			out.Diagnosis = append(out.Diagnosis, fmt.Sprintf(
+				"The last successful deployment was %s (set %s) at %s. Comparing set %s with set %s may show which change broke the environment.",
+				out.LastSucceeded.Id, out.LastSucceeded.SetId, out.LastSucceeded.StatusChangedAt.Format(time.RFC3339), out.LastSucceeded.SetId, deployment.SetId,
+			))
+		} else if len(timeline) > 1 {
+			out.Diagnosis = append(out.Diagnosis, fmt.Sprintf("None of the last %d deployments succeeded.", len(timeline)))
+		}
+		if len(errs) == 0 {
+			out.Diagnosis = append(out.Diagnosis, "The deployment failed without reporting errors, the workload runtime status or logs may contain more detail.")
+		}
+	}
+	if out.FailedInWindow > 1 {
+		out.Diagnosis = append(out.Diagnosis, fmt.Sprintf("%d of the last %d deployments failed.", out.FailedInWindow, len(timeline)))
+	}
+	return out
+}
+
+func NewGetHumanitecDeploymentStatus() mcp.Tool {
+	return mcp.Tool{
+		Name: "get_humanitec_deployment_status",
+		Description: `This tool returns the status of a deployment in a Humanitec Environment along with the errors reported for each workload and resource, and the timeline of the most recent deployments in the Environment.
+The result contains a condensed diagnosis that should be used to answer questions about why an Environment is broken or failing to deploy.
+If deployment_id is not provided, the latest deployment in the Environment is used.`,
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"org_id":        map[string]interface{}{"type": "string", "description": "The Humanitec Organization (org) ID to work with."},
+				"app_id":        map[string]interface{}{"type": "string", "description": "The Humanitec Application (app) ID to work with."},
+				"env_id":        map[string]interface{}{"type": "string", "description": "The Humanitec Environment (env) ID to work with."},
+				"deployment_id": map[string]interface{}{"type": "string", "description": "Optional Humanitec Deployment ID to inspect, defaults to the latest deployment"},
+				"history":       map[string]interface{}{"type": "integer", "description": "The number of recent deployments to include in the timeline, defaults to 5", "minimum": 1},
+			},
+			"required":             []string{"org_id", "app_id", "env_id"},
+			"additionalProperties": false,
+		},
+		Callable: func(ctx context.Context, m map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+			orgId, _ := m["org_id"].(string)
+			appId, _ := m["app_id"].(string)
+			envId, _ := m["env_id"].(string)
+			deploymentId, _ := m["deployment_id"].(string)
+			history := 5
+			if v, ok := m["history"].(float64); ok && v >= 1 {
+				history = int(v)
+			}
+
+			hc, err := humanitec.NewHumanitecClientWithCurrentToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			r, err := humanitec.CheckResponse(func() (*client.ListDeploymentsResponse, error) {
+				return hc.ListDeploymentsWithResponse(ctx, orgId, appId, envId, &client.ListDeploymentsParams{})
+			}).AndStatusCodeEq(http.StatusOK).RespAndError()
+			if err != nil {
+				return nil, err
+			}
+			deployments := *r.JSON200
+			slices.SortFunc(deployments, func(a, b client.DeploymentResponse) int {
+				return b.CreatedAt.Compare(a.CreatedAt)
+			})
+			if len(deployments) == 0 {
+				return []mcp.CallToolResponseContent{mcp.NewTextToolResponseContent("There are no deployments in Environment '%s' of Application '%s'.", envId, appId)}, nil
+			}
+
+			var deployment client.DeploymentResponse
+			if deploymentId == "" {
+				deployment = deployments[0]
+			} else if i := slices.IndexFunc(deployments, func(d client.DeploymentResponse) bool {
+				return d.Id == deploymentId
+			}); i >= 0 {
+				deployment = deployments[i]
+				deployments = deployments[i:]
+			} else {
+				return nil, fmt.Errorf("deployment '%s' does not exist in Environment '%s' of Application '%s'", deploymentId, envId, appId)
+			}
+			deployments = deployments[:min(history, len(deployments))]
+
+			var errs []client.DeploymentErrorResponse
+			if deployment.Status != deploymentStatusSucceeded {
+				if er, err := humanitec.CheckResponse(func() (*client.ListDeploymentErrorsResponse, error) {
+					return hc.ListDeploymentErrorsWithResponse(ctx, orgId, appId, envId, deployment.Id)
+				}).AndStatusCodeEq(http.StatusOK).RespAndError(); err != nil {
+					return nil, err
+				} else if er.JSON200 != nil {
+					errs = *er.JSON200
+				}
+			}
+
+			out := diagnoseDeployment(deployment, errs, deployments)
+			return []mcp.CallToolResponseContent{
+				mcp.NewTextToolResponseContent("Diagnosis of deployment %s in Environment '%s' of Application '%s':\n- %s", deployment.Id, envId, appId, strings.Join(out.Diagnosis, "\n- ")),
+				mcp.NewTextToolResponseContent("The full deployment status, errors and timeline in JSON format: %s", internal.PrettyJson(out)),
+			}, nil
+		},
+	}
+}
@@ -0,0 +1,107 @@
+package tools
+
+import (
+	"testing"
+	"time"
+
+	"github.com/humanitec/humanitec-go-autogen/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnoseDeployment(t *testing.T) {
+	at := func(minute int) time.Time {
+		return time.Date(2024, 1, 1, 10, minute, 0, 0, time.UTC)
+	}
+	deployment := func(id, setId, status string, minute int) client.DeploymentResponse {
+		return client.DeploymentResponse{Id: id, SetId: setId, Status: status, CreatedAt: at(minute), StatusChangedAt: at(minute + 1)}
+	}
+
+	for _, tc := range []struct {
+		name           string
+		deployment     client.DeploymentResponse
+		errs           []client.DeploymentErrorResponse
+		timeline       []client.DeploymentResponse
+		lastSucceeded  string
+		failedInWindow int
+		errorsByScope  map[string]map[string][]deploymentError
+		diagnosis      []string
+	}{
+		{
+			name:       "succeeded",
+			deployment: deployment("d2", "s2", "succeeded", 10),
+			timeline:   []client.DeploymentResponse{deployment("d2", "s2", "succeeded", 10), deployment("d1", "s1", "succeeded", 0)},
+			// the deployment itself is not its own last successful deployment
+			lastSucceeded: "d1",
+			diagnosis:     []string{"Deployment d2 succeeded at 2024-01-01T10:11:00Z."},
+		},
+		{
+			name:       "in progress",
+			deployment: deployment("d2", "s2", "in progress", 10),
+			diagnosis:  []string{"Deployment d2 is still in progress since 2024-01-01T10:10:00Z, the result is not known yet."},
+		},
+		{
+			name:       "unknown status",
+			deployment: deployment("d2", "s2", "mystery", 10),
+			diagnosis:  []string{"Deployment d2 has status 'mystery'."},
+		},
+		{
+			name:       "failed with errors after a success",
+			deployment: deployment("d3", "s3", "failed", 20),
+			errs: []client.DeploymentErrorResponse{
+				{Scope: "workload", ObjectId: "api", Code: "E1", Summary: "image pull failed", Message: "back-off pulling image"},
+				{Scope: "workload", ObjectId: "api", Message: "container crashed"},
+				{Message: "timed out"},
+			},
+			timeline: []client.DeploymentResponse{
+				deployment("d3", "s3", "failed", 20),
+				deployment("d2", "s2", "failed", 10),
+				deployment("d1", "s1", "succeeded", 0),
+			},
+			lastSucceeded:  "d1",
+			failedInWindow: 2,
+			errorsByScope: map[string]map[string][]deploymentError{
+				"workload": {"api": {
+					{Code: "E1", Summary: "image pull failed", Message: "back-off pulling image"},
+					{Message: "container crashed"},
+				}},
+				"deployment": {"-": {{Message: "timed out"}}},
+			},
+			diagnosis: []string{
+				"Deployment d3 failed at 2024-01-01T10:21:00Z.",
+				"deployment '-' reported 1 error(s), first: timed out",
+				"workload 'api' reported 2 error(s), first: image pull failed",
+				"The last successful deployment was d1 (set s1) at 2024-01-01T10:01:00Z. Comparing set s1 with set s3 may show which change broke the environment.",
+				"2 of the last 3 deployments failed.",
+			},
+		},
+		{
+			name:       "failed without errors or successes",
+			deployment: deployment("d2", "s2", "failed", 10),
+			timeline: []client.DeploymentResponse{
+				deployment("d2", "s2", "failed", 10),
+				deployment("d1", "s1", "failed", 0),
+			},
+			failedInWindow: 2,
+			diagnosis: []string{
+				"Deployment d2 failed at 2024-01-01T10:11:00Z.",
+				"None of the last 2 deployments succeeded.",
+				"The deployment failed without reporting errors, the workload runtime status or logs may contain more detail.",
+				"2 of the last 2 deployments failed.",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			out := diagnoseDeployment(tc.deployment, tc.errs, tc.timeline)
+			assert.Equal(t, tc.deployment.Id, out.Deployment.Id)
+			assert.Len(t, out.Timeline, len(tc.timeline))
+			if tc.lastSucceeded == "" {
+				assert.Nil(t, out.LastSucceeded)
+			} else if assert.NotNil(t, out.LastSucceeded) {
+				assert.Equal(t, tc.lastSucceeded, out.LastSucceeded.Id)
+			}
+			assert.Equal(t, tc.failedInWindow, out.FailedInWindow)
+			assert.Equal(t, tc.errorsByScope, out.ErrorsByScope)
+			assert.Equal(t, tc.diagnosis, out.Diagnosis)
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/humanitec/humanitec-go-autogen/client"
+
+	"github.com/humanitec/canyon-cli/internal"
+	"github.com/humanitec/canyon-cli/internal/clients/humanitec"
+	"github.com/humanitec/canyon-cli/internal/mcp"
+)
+
+// setDiffOperation is a single JSON-patch-like change between two deployment sets.
+type setDiffOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  interface{} `json:"from,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+type setDiff struct {
+	BaseSetId   string             `json:"baseSetId"`
+	TargetSetId string             `json:"targetSetId"`
+	Summary     []string           `json:"summary"`
+	Operations  []setDiffOperation `json:"operations"`
+}
+
+// toGenericJson converts a typed value into the generic map and slice form produced by encoding/json.
+func toGenericJson(v interface{}) interface{} {
+	raw, _ := json.Marshal(v)
+	var out interface{}
+	_ = json.Unmarshal(raw, &out)
+	return out
+}
+
+func jsonPointer(parts []string) string {
+	sb := new(strings.Builder)
+	for _, p := range parts {
+		sb.WriteByte('/')
+		sb.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(p))
+	}
+	return sb.String()
+}
+
+// diffGenericJson appends the operations needed to transform a into b. Objects are compared key by key while arrays
+// and scalars are replaced as a whole.
+func diffGenericJson(path []string, a, b interface{}, out *[]setDiffOperation) {
+	am, aIsMap := a.(map[string]interface{})
+	bm, bIsMap := b.(map[string]interface{})
+	if aIsMap && bIsMap {
+		keys := make([]string, 0, len(am)+len(bm))
+		for k := range am {
+			keys = append(keys, k)
+		}
+		for k := range bm {
+			if _, ok := am[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			av, inA := am[k]
+			bv, inB := bm[k]
+			subPath := append(slices.Clone(path), k)
+			switch {
+			case !inA:
+				*out = append(*out, setDiffOperation{Op: "add", Path: jsonPointer(subPath), Value: bv})
+			case !inB:
+				*out = append(*out, setDiffOperation{Op: "remove", Path: jsonPointer(subPath), From: av})
+			default:
+				diffGenericJson(subPath, av, bv, out)
+			}
+		}
+		return
+	}
+	if !reflect.DeepEqual(a, b) {
+		*out = append(*out, setDiffOperation{Op: "replace", Path: jsonPointer(path), From: a, Value: b})
+	}
+}
+
+// summarizeSetDiffOperation converts an operation into a short human-readable sentence based on where in the
+// deployment set it applies.
+func summarizeSetDiffOperation(path []string, op setDiffOperation) string {
+	verb := map[string]string{"add": "added", "remove": "removed", "replace": "changed"}[op.Op]
+	if len(path) >= 2 && path[0] == "shared" {
+		if len(path) == 2 {
+			return fmt.Sprintf("shared resource '%s' %s", path[1], verb)
+		}
+		return fmt.Sprintf("shared resource '%s' changed at %s", path[1], jsonPointer(path[2:]))
+	}
+	if len(path) < 2 || path[0] != "modules" {
+		return fmt.Sprintf("%s %s", jsonPointer(path), verb)
+	}
+	workload := path[1]
+	switch {
+	case len(path) == 2:
+		return fmt.Sprintf("workload '%s' %s", workload, verb)
+	case len(path) >= 4 && path[2] == "externals":
+		if len(path) == 4 {
+			return fmt.Sprintf("resource '%s' of workload '%s' %s", path[3], workload, verb)
+		}
+		return fmt.Sprintf("resource '%s' of workload '%s' changed at %s", path[3], workload, jsonPointer(path[4:]))
+	case len(path) >= 5 && path[2] == "spec" && path[3] == "containers":
+		container := path[4]
+		switch {
+		case len(path) == 5:
+			return fmt.Sprintf("container '%s' of workload '%s' %s", container, workload, verb)
+		case len(path) == 6 && path[5] == "image":
+			return fmt.Sprintf("image of container '%s' in workload '%s' changed from '%v' to '%v'", container, workload, op.From, op.Value)
+		case len(path) == 7 && path[5] == "variables":
+			return fmt.Sprintf("variable '%s' of container '%s' in workload '%s' %s", path[6], container, workload, verb)
+		case path[5] == "variables":
+			return fmt.Sprintf("variables of container '%s' in workload '%s' %s", container, workload, verb)
+		default:
+			return fmt.Sprintf("container '%s' of workload '%s' changed at %s", container, workload, jsonPointer(path[5:]))
+		}
+	default:
+		return fmt.Sprintf("workload '%s' changed at %s", workload, jsonPointer(path[2:]))
+	}
+}
+
+// diffDeploymentSets computes the structured difference needed to go from the base set to the target set.
+func diffDeploymentSets(base, target *client.SetResponse) setDiff {
+	out := setDiff{BaseSetId: base.Id, TargetSetId: target.Id, Summary: make([]string, 0), Operations: make([]setDiffOperation, 0)}
+	for _, section := range []struct {
+		name string
+		a, b interface{}
+	}{
+		{"modules", toGenericJson(base.Modules), toGenericJson(target.Modules)},
+		{"shared", toGenericJson(base.Shared), toGenericJson(target.Shared)},
+	} {
+		a, b := section.a, section.b
+		if a == nil {
+			a = map[string]interface{}{}
+		}
+		if b == nil {
+			b = map[string]interface{}{}
+		}
+		ops := make([]setDiffOperation, 0)
+		diffGenericJson([]string{section.name}, a, b, &ops)
+		for _, op := range ops {
+			out.Summary = append(out.Summary, summarizeSetDiffOperation(splitJsonPointer(op.Path), op))
+		}
+		out.Operations = append(out.Operations, ops...)
+	}
+	return out
+}
+
+func splitJsonPointer(p string) []string {
+	parts := strings.Split(strings.TrimPrefix(p, "/"), "/")
+	for i, part := range parts {
+		parts[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+	}
+	return parts
+}
+
+func NewDiffHumanitecDeploymentSets() mcp.Tool {
+	return mcp.Tool{
+		Name: "diff_humanitec_deployment_sets",
+		Description: `This tool computes the structured difference between two Humanitec Deployment Sets in the same Application.
+The sets can be given directly as base_set_id and target_set_id, or as base_env_id and target_env_id in which case the sets deployed in those Environments are compared.
+The result reports added, removed, and changed workloads, containers, variables, images, and shared resources as JSON-patch-like operations along with a human readable summary.
+This tool should be preferred over comparing the contents of deployment sets directly.`,
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"org_id":        map[string]interface{}{"type": "string", "description": "The Humanitec Organization (org) ID to work with."},
+				"app_id":        map[string]interface{}{"type": "string", "description": "The Humanitec Application (app) ID to work with."},
+				"base_set_id":   map[string]interface{}{"type": "string", "description": "The Deployment Set ID to compare from"},
+				"target_set_id": map[string]interface{}{"type": "string", "description": "The Deployment Set ID to compare to"},
+				"base_env_id":   map[string]interface{}{"type": "string", "description": "The Environment ID whose deployed set to compare from, used when base_set_id is not set"},
+				"target_env_id": map[string]interface{}{"type": "string", "description": "The Environment ID whose deployed set to compare to, used when target_set_id is not set"},
+			},
+			"required":             []string{"org_id", "app_id"},
+			"additionalProperties": false,
+		},
+		Callable: func(ctx context.Context, m map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+			orgId, _ := m["org_id"].(string)
+			appId, _ := m["app_id"].(string)
+			hc, err := humanitec.NewHumanitecClientWithCurrentToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			setIds := make([]string, 2)
+			for i, side := range []string{"base", "target"} {
+				if v, _ := m[side+"_set_id"].(string); v != "" {
+					setIds[i] = v
+				} else if v, _ := m[side+"_env_id"].(string); v != "" {
+					if setIds[i], err = getDeployedSetId(ctx, hc, orgId, appId, v); err != nil {
+						return nil, err
+					}
+				} else {
+					return nil, fmt.Errorf("either %s_set_id or %s_env_id must be provided", side, side)
+				}
+			}
+
+			base, err := getDeploymentSet(ctx, hc, orgId, appId, setIds[0])
+			if err != nil {
+				return nil, err
+			}
+			target, err := getDeploymentSet(ctx, hc, orgId, appId, setIds[1])
+			if err != nil {
+				return nil, err
+			}
+
+			out := diffDeploymentSets(base, target)
+			if len(out.Operations) == 0 {
+				return []mcp.CallToolResponseContent{mcp.NewTextToolResponseContent("Deployment Sets %s and %s have identical contents.", setIds[0], setIds[1])}, nil
+			}
+			return []mcp.CallToolResponseContent{
+				mcp.NewTextToolResponseContent("Changes from set %s to set %s:\n- %s", setIds[0], setIds[1], strings.Join(out.Summary, "\n- ")),
+				mcp.NewTextToolResponseContent("The full difference in JSON format: %s", internal.PrettyJson(out)),
+			}, nil
+		},
+	}
+}
@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/humanitec/humanitec-go-autogen/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffDeploymentSets(t *testing.T) {
+	base := &client.SetResponse{
+		Id: "base",
+		Modules: map[string]client.ModuleResponse{
+			"api": {Profile: "humanitec/default-module", Spec: map[string]interface{}{
+				"containers": map[string]interface{}{
+					"main": map[string]interface{}{
+						"image":     "registry/api:1.0.0",
+						"variables": map[string]interface{}{"LOG_LEVEL": "info", "REMOVED": "x"},
+					},
+				},
+			}, Externals: map[string]interface{}{"db": map[string]interface{}{"type": "postgres"}}},
+			"legacy": {Profile: "humanitec/default-module"},
+		},
+		Shared: map[string]interface{}{"dns": map[string]interface{}{"type": "dns"}},
+	}
+	target := &client.SetResponse{
+		Id: "target",
+		Modules: map[string]client.ModuleResponse{
+			"api": {Profile: "humanitec/default-module", Spec: map[string]interface{}{
+				"containers": map[string]interface{}{
+					"main": map[string]interface{}{
+						"image":     "registry/api:1.1.0",
+						"variables": map[string]interface{}{"LOG_LEVEL": "debug"},
+					},
+					"sidecar": map[string]interface{}{"image": "registry/proxy:2"},
+				},
+			}, Externals: map[string]interface{}{"db": map[string]interface{}{"type": "postgres"}}},
+			"worker": {Profile: "humanitec/default-module"},
+		},
+	}
+
+	out := diffDeploymentSets(base, target)
+	assert.Equal(t, "base", out.BaseSetId)
+	assert.Equal(t, "target", out.TargetSetId)
+	assert.Equal(t, []string{
+		"image of container 'main' in workload 'api' changed from 'registry/api:1.0.0' to 'registry/api:1.1.0'",
+		"variable 'LOG_LEVEL' of container 'main' in workload 'api' changed",
+		"variable 'REMOVED' of container 'main' in workload 'api' removed",
+		"container 'sidecar' of workload 'api' added",
+		"workload 'legacy' removed",
+		"workload 'worker' added",
+		"shared resource 'dns' removed",
+	}, out.Summary)
+	assert.Equal(t, setDiffOperation{Op: "replace", Path: "/modules/api/spec/containers/main/image", From: "registry/api:1.0.0", Value: "registry/api:1.1.0"}, out.Operations[0])
+	assert.Len(t, out.Operations, len(out.Summary))
+
+	assert.Empty(t, diffDeploymentSets(base, base).Operations)
+}
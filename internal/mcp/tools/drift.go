@@ -0,0 +1,192 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/humanitec/canyon-cli/internal"
+	"github.com/humanitec/canyon-cli/internal/clients/humanitec"
+	"github.com/humanitec/canyon-cli/internal/mcp"
+	"github.com/humanitec/canyon-cli/internal/ref"
+)
+
+const (
+	envFindingFailedDeployment = "failed_deployment"
+	envFindingDrift            = "drift"
+	envFindingStale            = "stale"
+	envFindingNeverDeployed    = "never_deployed"
+)
+
+// envFindingSeverity ranks the kinds of findings. A failed deployment means the environment is not in the state that
+// was intended, drift is a promotion gap, and stale environments are candidates for cleanup.
+var envFindingSeverity = map[string]int{
+	envFindingFailedDeployment: 3,
+	envFindingDrift:            2,
+	envFindingStale:            1,
+	envFindingNeverDeployed:    1,
+}
+
+var envFindingSeverityNames = map[int]string{3: "high", 2: "medium", 1: "low"}
+
+type envFinding struct {
+	AppId              string     `json:"appId"`
+	EnvId              string     `json:"envId"`
+	EnvType            string     `json:"envType"`
+	Kind               string     `json:"kind"`
+	Severity           string     `json:"severity"`
+	Detail             string     `json:"detail"`
+	LastDeploymentTime *time.Time `json:"lastDeploymentTime,omitempty"`
+	Differences        []string   `json:"differences,omitempty"`
+
+	severity int
+}
+
+// detectEnvironmentFindings flags the environments of the apps that failed their last deployment, were not deployed
+// within staleDays, or whose deployed set differs from the set deployed to the referenceEnvId of the same app. The
+// findings are ranked by severity and then by the oldest last deployment.
+func detectEnvironmentFindings(apps map[string]appstate, sets []deployedSet, referenceEnvId string, staleDays int, now time.Time) []envFinding {
+	out := make([]envFinding, 0)
+	add := func(appId, envId string, env envstate, kind, detail string, differences []string) {
+		f := envFinding{AppId: appId, EnvId: envId, EnvType: env.Type, Kind: kind, Detail: detail, Differences: differences, severity: envFindingSeverity[kind]}
+		f.Severity = envFindingSeverityNames[f.severity]
+		if !env.LastDeploymentTime.IsZero() {
+			f.LastDeploymentTime = &env.LastDeploymentTime
+		}
+		out = append(out, f)
+	}
+
+	setsByEnv := make(map[string]deployedSet, len(sets))
+	for _, ds := range sets {
+		setsByEnv[ds.AppId+"/"+ds.EnvId] = ds
+	}
+
+	cutoff := now.AddDate(0, 0, -staleDays)
+	for appId, app := range apps {
+		for envId, env := range app.Environments {
+			if env.LastDeploymentTime.IsZero() {
+				add(appId, envId, env, envFindingNeverDeployed, "the environment has never been deployed", nil)
+				continue
+			}
+			if env.LastDeploymentStatus == deploymentStatusFailed {
+				add(appId, envId, env, envFindingFailedDeployment, fmt.Sprintf("the last deployment %s failed", env.LastDeploymentId), nil)
+			}
+			if env.LastDeploymentTime.Before(cutoff) {
+				add(appId, envId, env, envFindingStale, fmt.Sprintf("no deployment in the last %d days", staleDays), nil)
+			}
+			if referenceEnvId == "" || envId == referenceEnvId {
+				continue
+			}
+			reference, refOk := setsByEnv[appId+"/"+referenceEnvId]
+			current, curOk := setsByEnv[appId+"/"+envId]
+			if !refOk || !curOk || reference.Set.Id == current.Set.Id {
+				continue
+			}
+			if d := diffDeploymentSets(reference.Set, current.Set); len(d.Operations) > 0 {
+				add(appId, envId, env, envFindingDrift, fmt.Sprintf("the deployed set differs from environment '%s' by %d changes", referenceEnvId, len(d.Operations)), d.Summary)
+			}
+		}
+	}
+
+	slices.SortFunc(out, func(a, b envFinding) int {
+		if c := cmp.Compare(b.severity, a.severity); c != 0 {
+			return c
+		}
+		var at, bt time.Time
+		if a.LastDeploymentTime != nil {
+			at = *a.LastDeploymentTime
+		}
+		if b.LastDeploymentTime != nil {
+			bt = *b.LastDeploymentTime
+		}
+		if c := at.Compare(bt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.AppId+"/"+a.EnvId+"/"+a.Kind, b.AppId+"/"+b.EnvId+"/"+b.Kind)
+	})
+	return out
+}
+
+func NewDetectEnvironmentDrift() mcp.Tool {
+	return mcp.Tool{
+		Name: "detect_stale_and_drifting_humanitec_environments",
+		Description: `This tool flags Humanitec Environments that need attention, ranked by severity:
+- high: the last deployment of the Environment failed.
+- medium: the deployed set differs from the set deployed to the reference_env_id of the same Application, for example staging compared to production. This shows promotion gaps.
+- low: the Environment was not deployed within stale_days or has never been deployed. These are candidates for cleanup.
+An optional app_id regex argument can filter Application Ids, while the env_type argument can filter the Environments that are checked by Environment Type.`,
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"org_id":           map[string]interface{}{"type": "string", "description": "The Humanitec Organization (org) ID to work with."},
+				"app_id":           map[string]interface{}{"type": "string", "description": "Optional regex pattern to filter for app id"},
+				"env_type":         map[string]interface{}{"type": "string", "description": "Optional filter for the environment type of the environments to check"},
+				"stale_days":       map[string]interface{}{"type": "integer", "description": "The number of days without a deployment after which an Environment is stale, defaults to 30", "minimum": 1},
+				"reference_env_id": map[string]interface{}{"type": "string", "description": "Optional Environment ID to compare the deployed sets of the other Environments in each Application against, for example production"},
+			},
+			"required":             []string{"org_id"},
+			"additionalProperties": false,
+		},
+		Annotations: &mcp.ToolAnnotations{Title: "Detect stale and drifting environments", ReadOnlyHint: ref.Ref(true)},
+		Callable: func(ctx context.Context, m map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+			orgId, _ := m["org_id"].(string)
+			envTypeFilter, _ := m["env_type"].(string)
+			referenceEnvId, _ := m["reference_env_id"].(string)
+			staleDays := 30
+			if v, ok := m["stale_days"].(float64); ok && v >= 1 {
+				staleDays = int(v)
+			}
+			var appIdPattern *regexp.Regexp
+			if v, ok := m["app_id"].(string); ok {
+				var err error
+				if appIdPattern, err = regexp.CompilePOSIX(v); err != nil {
+					return nil, fmt.Errorf("invalid app_id  regex: %w", err)
+				}
+			}
+
+			hc, err := humanitec.NewHumanitecClientWithCurrentToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+			// The environment type filter is applied here rather than when listing so that the reference environment is
+			// kept regardless of its type.
+			apps, appErrors, err := listAppsAndEnvs(ctx, hc, orgId, appIdPattern, "")
+			if err != nil {
+				return nil, err
+			}
+			checked := make(map[string]appstate, len(apps))
+			compared := make(map[string]appstate)
+			for appId, app := range apps {
+				envs := make(map[string]envstate)
+				for envId, env := range app.Environments {
+					if envTypeFilter == "" || env.Type == envTypeFilter {
+						envs[envId] = env
+					}
+				}
+				checked[appId] = appstate{Name: app.Name, CreatedTime: app.CreatedTime, Environments: envs}
+				if referenceEnv, ok := app.Environments[referenceEnvId]; ok && referenceEnvId != "" {
+					withReference := make(map[string]envstate, len(envs)+1)
+					for envId, env := range envs {
+						withReference[envId] = env
+					}
+					withReference[referenceEnvId] = referenceEnv
+					compared[appId] = appstate{Name: app.Name, CreatedTime: app.CreatedTime, Environments: withReference}
+				}
+			}
+
+			sets, setsErr := fetchDeployedSets(ctx, hc, orgId, compared)
+			findings := detectEnvironmentFindings(checked, sets, referenceEnvId, staleDays, time.Now())
+			out := []mcp.CallToolResponseContent{
+				mcp.NewTextToolResponseContent("The following Environments were flagged in Organization '%s', ranked by severity, in JSON format: %s", orgId, internal.PrettyJson(findings)),
+			}
+			if setsErr != nil {
+				out = append(out, mcp.NewTextToolResponseContent("Some deployment sets could not be fetched so drift may be incomplete: %v", setsErr.Error()))
+			}
+			return append(out, appErrorsResponse(appErrors)...), nil
+		},
+	}
+}
@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"testing"
+	"time"
+
+	"github.com/humanitec/humanitec-go-autogen/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectEnvironmentFindings(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	apps := map[string]appstate{
+		"shop": {Environments: map[string]envstate{
+			"production": {Type: "production", LastDeploymentTime: now.AddDate(0, 0, -2), LastDeploymentStatus: deploymentStatusSucceeded},
+			"staging":    {Type: "staging", LastDeploymentTime: now.AddDate(0, 0, -1), LastDeploymentStatus: deploymentStatusFailed, LastDeploymentId: "d1"},
+			"preview":    {Type: "development", LastDeploymentTime: now.AddDate(0, 0, -60), LastDeploymentStatus: deploymentStatusSucceeded},
+			"empty":      {Type: "development"},
+		}},
+	}
+	set := func(id, image string) *client.SetResponse {
+		return &client.SetResponse{Id: id, Modules: map[string]client.ModuleResponse{
+			"api": {Spec: map[string]interface{}{"containers": map[string]interface{}{"main": map[string]interface{}{"image": image}}}},
+		}}
+	}
+	findings := detectEnvironmentFindings(apps, []deployedSet{
+		{AppId: "shop", EnvId: "production", Set: set("a", "api:1")},
+		{AppId: "shop", EnvId: "staging", Set: set("b", "api:2")},
+		{AppId: "shop", EnvId: "preview", Set: set("a", "api:1")},
+	}, "production", 30, now)
+
+	kinds := make([]string, len(findings))
+	for i, f := range findings {
+		kinds[i] = f.Severity + ":" + f.Kind + ":" + f.EnvId
+	}
+	assert.Equal(t, []string{
+		"high:failed_deployment:staging",
+		"medium:drift:staging",
+		"low:never_deployed:empty",
+		"low:stale:preview",
+	}, kinds)
+	assert.NotEmpty(t, findings[1].Differences)
+}
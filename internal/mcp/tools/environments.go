@@ -0,0 +1,182 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/humanitec/humanitec-go-autogen/client"
+
+	"github.com/humanitec/canyon-cli/internal"
+	"github.com/humanitec/canyon-cli/internal/clients/humanitec"
+	"github.com/humanitec/canyon-cli/internal/mcp"
+	"github.com/humanitec/canyon-cli/internal/ref"
+)
+
+func NewListEnvironmentTypes() mcp.Tool {
+	return mcp.Tool{
+		Name:        "list_humanitec_environment_types",
+		Description: `This tool returns the environment types defined in a Humanitec Organization along with their descriptions. Every Environment has exactly one environment type.`,
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"org_id": map[string]interface{}{"type": "string", "description": "The Humanitec Organization (org) ID to work with."},
+			},
+			"required":             []string{"org_id"},
+			"additionalProperties": false,
+		},
+		Annotations: &mcp.ToolAnnotations{Title: "List environment types", ReadOnlyHint: ref.Ref(true)},
+		Callable: func(ctx context.Context, m map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+			orgId, _ := m["org_id"].(string)
+			hc, err := humanitec.NewHumanitecClientWithCurrentToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+			r, err := humanitec.CheckResponse(func() (*client.ListEnvironmentTypesResponse, error) {
+				return hc.ListEnvironmentTypesWithResponse(ctx, orgId)
+			}).AndStatusCodeEq(http.StatusOK).RespAndError()
+			if err != nil {
+				return nil, err
+			}
+			envTypes := *r.JSON200
+			slices.SortFunc(envTypes, func(a, b client.EnvironmentTypeResponse) int {
+				return strings.Compare(a.Id, b.Id)
+			})
+			return []mcp.CallToolResponseContent{
+				mcp.NewTextToolResponseContent("The Organization '%s' has the following environment types in JSON format: %s", orgId, internal.PrettyJson(envTypes)),
+			}, nil
+		},
+	}
+}
+
+func NewCreateEnvironment() mcp.Tool {
+	return mcp.Tool{
+		Name: "create_humanitec_environment",
+		Description: `This tool creates a new Environment in a Humanitec Application, either empty or by cloning an existing Environment.
+When from_env_id is set, the new Environment starts from a deployment of the source Environment. By default this is the most recent deployment of the source Environment, but any previous deployment_id of the source Environment can be chosen instead.
+The environment type defaults to the type of the source Environment, or to the default environment type of the Organization for an empty Environment. The new Environment is not deployed until a deployment is triggered.`,
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"org_id":        map[string]interface{}{"type": "string", "description": "The Humanitec Organization (org) ID to work with."},
+				"app_id":        map[string]interface{}{"type": "string", "description": "The Humanitec Application (app) ID to work with."},
+				"env_id":        map[string]interface{}{"type": "string", "description": "The ID of the new Environment."},
+				"env_name":      map[string]interface{}{"type": "string", "description": "The display name of the new Environment, defaults to the env_id."},
+				"env_type":      map[string]interface{}{"type": "string", "description": "The environment type of the new Environment, defaults to the type of the source Environment or the default environment type of the Organization."},
+				"from_env_id":   map[string]interface{}{"type": "string", "description": "Optional Environment to clone. When omitted, an empty Environment is created."},
+				"deployment_id": map[string]interface{}{"type": "string", "description": "Optional deployment of the source Environment to clone, defaults to the latest deployment."},
+			},
+			"required":             []string{"org_id", "app_id", "env_id"},
+			"additionalProperties": false,
+		},
+		Annotations: &mcp.ToolAnnotations{Title: "Create or clone environment", ReadOnlyHint: ref.Ref(false), DestructiveHint: ref.Ref(false), IdempotentHint: ref.Ref(false)},
+		Callable: func(ctx context.Context, m map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+			orgId, _ := m["org_id"].(string)
+			appId, _ := m["app_id"].(string)
+			envId, _ := m["env_id"].(string)
+			envName, _ := m["env_name"].(string)
+			envType, _ := m["env_type"].(string)
+			fromEnvId, _ := m["from_env_id"].(string)
+			deploymentId, _ := m["deployment_id"].(string)
+			if envId == "" {
+				return nil, fmt.Errorf("env_id is required")
+			} else if fromEnvId == "" && deploymentId != "" {
+				return nil, fmt.Errorf("from_env_id is required to clone deployment '%s'", deploymentId)
+			}
+
+			hc, err := humanitec.NewHumanitecClientWithCurrentToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+			body := client.CreateEnvironmentJSONRequestBody{Id: envId, Name: ref.Coalesce(envName, envId)}
+			if envType != "" {
+				body.Type = ref.Ref(envType)
+			}
+			if fromEnvId == "" {
+				r, err := humanitec.CheckResponse(func() (*client.CreateEnvironmentResponse, error) {
+					return hc.CreateEnvironmentWithResponse(ctx, orgId, appId, body)
+				}).AndStatusCodeEq(http.StatusCreated).RespAndError()
+				if err != nil {
+					return nil, err
+				}
+				return []mcp.CallToolResponseContent{
+					mcp.NewTextToolResponseContent("The empty Environment was created: %s", internal.PrettyJson(r.JSON201)),
+				}, nil
+			}
+
+			src, err := humanitec.CheckResponse(func() (*client.GetEnvironmentResponse, error) {
+				return hc.GetEnvironmentWithResponse(ctx, orgId, appId, fromEnvId)
+			}).AndStatusCodeEq(http.StatusOK).RespAndError()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get source environment: %w", err)
+			}
+			if deploymentId == "" {
+				if src.JSON200.LastDeploy == nil {
+					return nil, fmt.Errorf("the source environment '%s' has never been deployed", fromEnvId)
+				}
+				deploymentId = src.JSON200.LastDeploy.Id
+			} else if _, err := humanitec.CheckResponse(func() (*client.GetDeploymentResponse, error) {
+				return hc.GetDeploymentWithResponse(ctx, orgId, appId, fromEnvId, deploymentId)
+			}).AndStatusCodeEq(http.StatusOK).RespAndError(); err != nil {
+				return nil, fmt.Errorf("failed to find deployment '%s' in the source environment: %w", deploymentId, err)
+			}
+
+			body.Type = ref.Ref(ref.Coalesce(envType, src.JSON200.Type))
+			body.FromDeployId = ref.Ref(deploymentId)
+			r, err := humanitec.CheckResponse(func() (*client.CreateEnvironmentResponse, error) {
+				return hc.CreateEnvironmentWithResponse(ctx, orgId, appId, body)
+			}).AndStatusCodeEq(http.StatusCreated).RespAndError()
+			if err != nil {
+				return nil, err
+			}
+			return []mcp.CallToolResponseContent{
+				mcp.NewTextToolResponseContent("The Environment was created from deployment '%s' of Environment '%s': %s", deploymentId, fromEnvId, internal.PrettyJson(r.JSON201)),
+			}, nil
+		},
+	}
+}
+
+func NewDeleteEnvironment() mcp.Tool {
+	return mcp.Tool{
+		Name: "delete_humanitec_environment",
+		Description: `This tool deletes an Environment from a Humanitec Application. All active resources of the Environment are deprovisioned and the workloads are removed from the cluster.
+This cannot be undone. Before calling this tool, always confirm with the user that this Environment should be deleted and then set confirm to true.`,
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"org_id":  map[string]interface{}{"type": "string", "description": "The Humanitec Organization (org) ID to work with."},
+				"app_id":  map[string]interface{}{"type": "string", "description": "The Humanitec Application (app) ID to work with."},
+				"env_id":  map[string]interface{}{"type": "string", "description": "The Humanitec Environment (env) ID to delete."},
+				"confirm": map[string]interface{}{"type": "boolean", "description": "Must be true to confirm that the user wants to delete the Environment."},
+			},
+			"required":             []string{"org_id", "app_id", "env_id", "confirm"},
+			"additionalProperties": false,
+		},
+		Annotations: &mcp.ToolAnnotations{Title: "Delete environment", ReadOnlyHint: ref.Ref(false), DestructiveHint: ref.Ref(true), IdempotentHint: ref.Ref(true)},
+		Callable: func(ctx context.Context, m map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+			orgId, _ := m["org_id"].(string)
+			appId, _ := m["app_id"].(string)
+			envId, _ := m["env_id"].(string)
+			if envId == "" {
+				return nil, fmt.Errorf("env_id is required")
+			} else if confirm, _ := m["confirm"].(bool); !confirm {
+				return nil, fmt.Errorf("the deletion of environment '%s' must be confirmed by the user", envId)
+			}
+
+			hc, err := humanitec.NewHumanitecClientWithCurrentToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := humanitec.CheckResponse(func() (*client.DeleteEnvironmentResponse, error) {
+				return hc.DeleteEnvironmentWithResponse(ctx, orgId, appId, envId)
+			}).AndStatusCodeEq(http.StatusNoContent, http.StatusAccepted, http.StatusOK).RespAndError(); err != nil {
+				return nil, err
+			}
+			return []mcp.CallToolResponseContent{
+				mcp.NewTextToolResponseContent("The Environment '%s' of Application '%s' is being deleted.", envId, appId),
+			}, nil
+		},
+	}
+}
@@ -0,0 +1,35 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateEnvironmentValidation(t *testing.T) {
+	for _, tc := range []struct {
+		arguments map[string]interface{}
+		err       string
+	}{
+		{map[string]interface{}{"org_id": "org", "app_id": "app"}, "env_id is required"},
+		{map[string]interface{}{"org_id": "org", "app_id": "app", "env_id": ""}, "env_id is required"},
+		{map[string]interface{}{"org_id": "org", "app_id": "app", "env_id": "dev", "deployment_id": "abc"}, "from_env_id is required to clone deployment 'abc'"},
+	} {
+		_, err := NewCreateEnvironment().Callable(context.Background(), tc.arguments)
+		assert.EqualError(t, err, tc.err)
+	}
+}
+
+func TestDeleteEnvironmentValidation(t *testing.T) {
+	_, err := NewDeleteEnvironment().Callable(context.Background(), map[string]interface{}{"org_id": "org", "app_id": "app", "env_id": "", "confirm": true})
+	assert.EqualError(t, err, "env_id is required")
+	_, err = NewDeleteEnvironment().Callable(context.Background(), map[string]interface{}{"org_id": "org", "app_id": "app", "env_id": "dev"})
+	assert.EqualError(t, err, "the deletion of environment 'dev' must be confirmed by the user")
+}
+
+func TestEnvironmentToolAnnotations(t *testing.T) {
+	assert.True(t, *NewListEnvironmentTypes().Annotations.ReadOnlyHint)
+	assert.False(t, *NewCreateEnvironment().Annotations.DestructiveHint)
+	assert.True(t, *NewDeleteEnvironment().Annotations.DestructiveHint)
+}
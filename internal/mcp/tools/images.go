@@ -0,0 +1,196 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/humanitec/canyon-cli/internal"
+	"github.com/humanitec/canyon-cli/internal/clients/humanitec"
+	"github.com/humanitec/canyon-cli/internal/mcp"
+	"github.com/humanitec/canyon-cli/internal/ref"
+)
+
+type imageReference struct {
+	Repository string `json:"repository"`
+	Tag        string `json:"tag,omitempty"`
+	Digest     string `json:"digest,omitempty"`
+}
+
+// version is the tag and digest of the image. Images without either use the implicit latest tag.
+func (r imageReference) version() string {
+	switch {
+	case r.Digest != "" && r.Tag != "":
+		return r.Tag + "@" + r.Digest
+	case r.Digest != "":
+		return "@" + r.Digest
+	default:
+		return r.Tag
+	}
+}
+
+// parseImageReference splits an image reference of the form [registry[:port]/]repository[:tag][@digest]. The
+// registry port is not mistaken for a tag since the tag must come after the last slash.
+func parseImageReference(image string) imageReference {
+	out := imageReference{Repository: image}
+	if i := strings.Index(out.Repository, "@"); i >= 0 {
+		out.Repository, out.Digest = out.Repository[:i], out.Repository[i+1:]
+	}
+	if i := strings.LastIndex(out.Repository, ":"); i > strings.LastIndex(out.Repository, "/") {
+		out.Repository, out.Tag = out.Repository[:i], out.Repository[i+1:]
+	}
+	if out.Tag == "" && out.Digest == "" {
+		out.Tag = "latest"
+	}
+	return out
+}
+
+type imageUsage struct {
+	AppId     string `json:"appId"`
+	EnvId     string `json:"envId"`
+	EnvType   string `json:"envType"`
+	Workload  string `json:"workload"`
+	Container string `json:"container"`
+}
+
+type imageVersion struct {
+	Version string       `json:"version"`
+	Usages  []imageUsage `json:"usages"`
+}
+
+type imageRepository struct {
+	Repository string         `json:"repository"`
+	Versions   []imageVersion `json:"versions"`
+}
+
+// imageSkew is a container of a workload that runs different versions of the same repository across the environments
+// of an application.
+type imageSkew struct {
+	AppId      string            `json:"appId"`
+	Workload   string            `json:"workload"`
+	Container  string            `json:"container"`
+	Repository string            `json:"repository"`
+	Versions   map[string]string `json:"versions"`
+}
+
+type imageInventory struct {
+	Repositories []imageRepository `json:"repositories"`
+	Skew         []imageSkew       `json:"skew"`
+}
+
+// buildImageInventory groups the container images of the deployed sets by repository and version and finds the
+// workload containers whose version differs between environments of the same app.
+func buildImageInventory(sets []deployedSet, repositoryFilter *regexp.Regexp) imageInventory {
+	versions := make(map[string]map[string][]imageUsage)
+	type containerKey struct{ appId, workload, container, repository string }
+	perContainer := make(map[containerKey]map[string]string)
+	for _, ds := range sets {
+		for _, ci := range deploymentSetImages(ds.Set) {
+			ir := parseImageReference(ci.Image)
+			if repositoryFilter != nil && !repositoryFilter.MatchString(ir.Repository) {
+				continue
+			}
+			if versions[ir.Repository] == nil {
+				versions[ir.Repository] = make(map[string][]imageUsage)
+			}
+			versions[ir.Repository][ir.version()] = append(versions[ir.Repository][ir.version()], imageUsage{
+				AppId: ds.AppId, EnvId: ds.EnvId, EnvType: ds.EnvType, Workload: ci.Workload, Container: ci.Container,
+			})
+			k := containerKey{ds.AppId, ci.Workload, ci.Container, ir.Repository}
+			if perContainer[k] == nil {
+				perContainer[k] = make(map[string]string)
+			}
+			perContainer[k][ds.EnvId] = ir.version()
+		}
+	}
+
+	out := imageInventory{Repositories: make([]imageRepository, 0, len(versions)), Skew: make([]imageSkew, 0)}
+	for repository, byVersion := range versions {
+		r := imageRepository{Repository: repository, Versions: make([]imageVersion, 0, len(byVersion))}
+		for version, usages := range byVersion {
+			slices.SortFunc(usages, func(a, b imageUsage) int {
+				return strings.Compare(a.AppId+"/"+a.EnvId+"/"+a.Workload+"/"+a.Container, b.AppId+"/"+b.EnvId+"/"+b.Workload+"/"+b.Container)
+			})
+			r.Versions = append(r.Versions, imageVersion{Version: version, Usages: usages})
+		}
+		slices.SortFunc(r.Versions, func(a, b imageVersion) int {
+			return strings.Compare(a.Version, b.Version)
+		})
+		out.Repositories = append(out.Repositories, r)
+	}
+	slices.SortFunc(out.Repositories, func(a, b imageRepository) int {
+		return strings.Compare(a.Repository, b.Repository)
+	})
+
+	for k, byEnv := range perContainer {
+		distinct := make(map[string]bool)
+		for _, v := range byEnv {
+			distinct[v] = true
+		}
+		if len(distinct) > 1 {
+			out.Skew = append(out.Skew, imageSkew{AppId: k.appId, Workload: k.workload, Container: k.container, Repository: k.repository, Versions: byEnv})
+		}
+	}
+	slices.SortFunc(out.Skew, func(a, b imageSkew) int {
+		return strings.Compare(a.AppId+"/"+a.Workload+"/"+a.Container+"/"+a.Repository, b.AppId+"/"+b.Workload+"/"+b.Container+"/"+b.Repository)
+	})
+	return out
+}
+
+func NewListContainerImages() mcp.Tool {
+	return mcp.Tool{
+		Name: "list_humanitec_container_images",
+		Description: `This tool returns the container images deployed to the Environments of a Humanitec Organization grouped by image repository and version (tag and digest), along with the Applications, Environments, Workloads, and containers that use each version.
+It also reports version skew: Workload containers that run different versions of the same image repository in different Environments of the same Application.
+Use this to answer which Environments still run a given image tag. The images are taken from the latest deployment of each Environment.
+An optional app_id regex argument can filter Application Ids, the env_type argument can filter by Environment Type, and the repository regex argument can filter image repositories.`,
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"org_id":     map[string]interface{}{"type": "string", "description": "The Humanitec Organization (org) ID to work with."},
+				"app_id":     map[string]interface{}{"type": "string", "description": "Optional regex pattern to filter for app id"},
+				"env_type":   map[string]interface{}{"type": "string", "description": "Optional filter for a specific environment type"},
+				"repository": map[string]interface{}{"type": "string", "description": "Optional regex pattern to filter image repositories, for example registry.example.com/team/api"},
+			},
+			"required":             []string{"org_id"},
+			"additionalProperties": false,
+		},
+		Annotations: &mcp.ToolAnnotations{Title: "List container images", ReadOnlyHint: ref.Ref(true)},
+		Callable: func(ctx context.Context, m map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+			orgId, _ := m["org_id"].(string)
+			envTypeFilter, _ := m["env_type"].(string)
+			var appIdPattern, repositoryPattern *regexp.Regexp
+			if v, ok := m["app_id"].(string); ok {
+				var err error
+				if appIdPattern, err = regexp.CompilePOSIX(v); err != nil {
+					return nil, fmt.Errorf("invalid app_id  regex: %w", err)
+				}
+			}
+			if v, ok := m["repository"].(string); ok && v != "" {
+				var err error
+				if repositoryPattern, err = regexp.CompilePOSIX(v); err != nil {
+					return nil, fmt.Errorf("invalid repository regex: %w", err)
+				}
+			}
+
+			hc, err := humanitec.NewHumanitecClientWithCurrentToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+			apps, appErrors, err := listAppsAndEnvs(ctx, hc, orgId, appIdPattern, envTypeFilter)
+			if err != nil {
+				return nil, err
+			}
+			sets, setsErr := fetchDeployedSets(ctx, hc, orgId, apps)
+			out := []mcp.CallToolResponseContent{
+				mcp.NewTextToolResponseContent("The container images deployed in Organization '%s' in JSON format: %s", orgId, internal.PrettyJson(buildImageInventory(sets, repositoryPattern))),
+			}
+			if setsErr != nil {
+				out = append(out, mcp.NewTextToolResponseContent("Some deployment sets could not be fetched so the images are incomplete: %v", setsErr.Error()))
+			}
+			return append(out, appErrorsResponse(appErrors)...), nil
+		},
+	}
+}
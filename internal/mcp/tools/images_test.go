@@ -0,0 +1,44 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/humanitec/humanitec-go-autogen/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseImageReference(t *testing.T) {
+	for image, expected := range map[string]imageReference{
+		"nginx":                          {Repository: "nginx", Tag: "latest"},
+		"nginx:1.27":                     {Repository: "nginx", Tag: "1.27"},
+		"registry:5000/team/api":         {Repository: "registry:5000/team/api", Tag: "latest"},
+		"registry:5000/team/api:v2":      {Repository: "registry:5000/team/api", Tag: "v2"},
+		"ghcr.io/team/api@sha256:abc":    {Repository: "ghcr.io/team/api", Digest: "sha256:abc"},
+		"ghcr.io/team/api:v1@sha256:abc": {Repository: "ghcr.io/team/api", Tag: "v1", Digest: "sha256:abc"},
+	} {
+		assert.Equal(t, expected, parseImageReference(image), image)
+	}
+}
+
+func TestBuildImageInventory(t *testing.T) {
+	set := func(image string) *client.SetResponse {
+		return &client.SetResponse{Modules: map[string]client.ModuleResponse{
+			"api": {Spec: map[string]interface{}{"containers": map[string]interface{}{
+				"main":    map[string]interface{}{"image": image},
+				"sidecar": map[string]interface{}{"image": "envoy:1.30"},
+			}}},
+		}}
+	}
+	inventory := buildImageInventory([]deployedSet{
+		{AppId: "shop", EnvId: "development", Set: set("api:2")},
+		{AppId: "shop", EnvId: "production", Set: set("api:1")},
+	}, nil)
+
+	assert.Len(t, inventory.Repositories, 2)
+	assert.Equal(t, "api", inventory.Repositories[0].Repository)
+	assert.Equal(t, []string{"1", "2"}, []string{inventory.Repositories[0].Versions[0].Version, inventory.Repositories[0].Versions[1].Version})
+	assert.Len(t, inventory.Repositories[1].Versions[0].Usages, 2)
+	assert.Equal(t, []imageSkew{
+		{AppId: "shop", Workload: "api", Container: "main", Repository: "api", Versions: map[string]string{"development": "2", "production": "1"}},
+	}, inventory.Skew)
+}
@@ -0,0 +1,219 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/humanitec/humanitec-go-autogen/client"
+
+	"github.com/humanitec/canyon-cli/internal"
+	"github.com/humanitec/canyon-cli/internal/clients/humanitec"
+	"github.com/humanitec/canyon-cli/internal/mcp"
+)
+
+// containerImage is the image of a single container of a workload in a deployment set.
+type containerImage struct {
+	Workload  string
+	Container string
+	Image     string
+}
+
+// deploymentSetImages returns the container images of every workload in the set, sorted by workload and container.
+func deploymentSetImages(set *client.SetResponse) []containerImage {
+	out := make([]containerImage, 0)
+	for workload, module := range set.Modules {
+		containers, _ := module.Spec["containers"].(map[string]interface{})
+		for name, raw := range containers {
+			c, _ := raw.(map[string]interface{})
+			if image, ok := c["image"].(string); ok && image != "" {
+				out = append(out, containerImage{Workload: workload, Container: name, Image: image})
+			}
+		}
+	}
+	slices.SortFunc(out, func(a, b containerImage) int {
+		return strings.Compare(a.Workload+"/"+a.Container, b.Workload+"/"+b.Container)
+	})
+	return out
+}
+
+type inventoryStaleEnv struct {
+	AppId              string     `json:"appId"`
+	EnvId              string     `json:"envId"`
+	EnvType            string     `json:"envType"`
+	LastDeploymentTime *time.Time `json:"lastDeploymentTime,omitempty"`
+}
+
+type inventoryImage struct {
+	Image     string   `json:"image"`
+	Workloads []string `json:"workloads"`
+}
+
+type inventoryReport struct {
+	Applications        int                 `json:"applications"`
+	Environments        int                 `json:"environments"`
+	EnvironmentsPerType map[string]int      `json:"environmentsPerType"`
+	StaleDays           int                 `json:"staleDays"`
+	StaleEnvironments   []inventoryStaleEnv `json:"staleEnvironments"`
+	Workloads           int                 `json:"workloads"`
+	WorkloadsPerProfile map[string]int      `json:"workloadsPerProfile"`
+	Images              []inventoryImage    `json:"images"`
+}
+
+// buildInventoryReport aggregates the apps and their deployed sets. Workloads are counted once per application, so a
+// workload deployed to several environments is only counted once, while images list every app/env/workload using them.
+func buildInventoryReport(apps map[string]appstate, sets []deployedSet, staleDays int, now time.Time) inventoryReport {
+	out := inventoryReport{
+		Applications:        len(apps),
+		EnvironmentsPerType: make(map[string]int),
+		StaleDays:           staleDays,
+		StaleEnvironments:   make([]inventoryStaleEnv, 0),
+		WorkloadsPerProfile: make(map[string]int),
+		Images:              make([]inventoryImage, 0),
+	}
+	cutoff := now.AddDate(0, 0, -staleDays)
+	for appId, app := range apps {
+		for envId, env := range app.Environments {
+			out.Environments++
+			out.EnvironmentsPerType[env.Type]++
+			if env.LastDeploymentTime.IsZero() {
+				out.StaleEnvironments = append(out.StaleEnvironments, inventoryStaleEnv{AppId: appId, EnvId: envId, EnvType: env.Type})
+			} else if env.LastDeploymentTime.Before(cutoff) {
+				out.StaleEnvironments = append(out.StaleEnvironments, inventoryStaleEnv{AppId: appId, EnvId: envId, EnvType: env.Type, LastDeploymentTime: &env.LastDeploymentTime})
+			}
+		}
+	}
+	slices.SortFunc(out.StaleEnvironments, func(a, b inventoryStaleEnv) int {
+		return strings.Compare(a.AppId+"/"+a.EnvId, b.AppId+"/"+b.EnvId)
+	})
+
+	seenWorkloads := make(map[string]bool)
+	images := make(map[string][]string)
+	for _, ds := range sets {
+		for workload, module := range ds.Set.Modules {
+			if k := ds.AppId + "/" + workload; !seenWorkloads[k] {
+				seenWorkloads[k] = true
+				out.Workloads++
+				out.WorkloadsPerProfile[module.Profile]++
+			}
+		}
+		for _, ci := range deploymentSetImages(ds.Set) {
+			id := ds.AppId + "/" + ds.EnvId + "/" + ci.Workload
+			if !slices.Contains(images[ci.Image], id) {
+				images[ci.Image] = append(images[ci.Image], id)
+			}
+		}
+	}
+	for image, workloads := range images {
+		slices.Sort(workloads)
+		out.Images = append(out.Images, inventoryImage{Image: image, Workloads: workloads})
+	}
+	slices.SortFunc(out.Images, func(a, b inventoryImage) int {
+		return strings.Compare(a.Image, b.Image)
+	})
+	return out
+}
+
+// toCsv flattens the report into section,name,value rows for the csv table renderer.
+func (r inventoryReport) toCsv() string {
+	buff := new(bytes.Buffer)
+	w := csv.NewWriter(buff)
+	_ = w.Write([]string{"section", "name", "value"})
+	_ = w.Write([]string{"totals", "applications", strconv.Itoa(r.Applications)})
+	_ = w.Write([]string{"totals", "environments", strconv.Itoa(r.Environments)})
+	_ = w.Write([]string{"totals", "workloads", strconv.Itoa(r.Workloads)})
+	writeCounts := func(section string, counts map[string]int) {
+		keys := make([]string, 0, len(counts))
+		for k := range counts {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			_ = w.Write([]string{section, k, strconv.Itoa(counts[k])})
+		}
+	}
+	writeCounts("environments per type", r.EnvironmentsPerType)
+	writeCounts("workloads per profile", r.WorkloadsPerProfile)
+	for _, e := range r.StaleEnvironments {
+		last := "never deployed"
+		if e.LastDeploymentTime != nil {
+			last = e.LastDeploymentTime.Format(time.RFC3339)
+		}
+		_ = w.Write([]string{fmt.Sprintf("stale environments (%d days)", r.StaleDays), e.AppId + "/" + e.EnvId, last})
+	}
+	for _, i := range r.Images {
+		_ = w.Write([]string{"images", i.Image, strings.Join(i.Workloads, " ")})
+	}
+	w.Flush()
+	return buff.String()
+}
+
+func NewGetOrganizationInventory() mcp.Tool {
+	return mcp.Tool{
+		Name: "get_humanitec_organization_inventory",
+		Description: `This tool returns an inventory report of a Humanitec Organization: the number of Applications, Environments per environment type, stale Environments that were not deployed within stale_days, Workloads per workload profile, and the container images in use along with the Workloads using them.
+The report is built from the latest deployment of each Environment. An optional app_id regex argument can filter Application Ids, while the env_type argument can filter by Environment Type.
+Set render_csv to also render the report as an HTML table with the csv table renderer and return its link.`,
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"org_id":     map[string]interface{}{"type": "string", "description": "The Humanitec Organization (org) ID to work with."},
+				"app_id":     map[string]interface{}{"type": "string", "description": "Optional regex pattern to filter for app id"},
+				"env_type":   map[string]interface{}{"type": "string", "description": "Optional filter for a specific environment type"},
+				"stale_days": map[string]interface{}{"type": "integer", "description": "The number of days without a deployment after which an Environment is stale, defaults to 30", "minimum": 1},
+				"render_csv": map[string]interface{}{"type": "boolean", "description": "Whether to render the report as an HTML table and return the link"},
+			},
+			"required":             []string{"org_id"},
+			"additionalProperties": false,
+		},
+		Callable: func(ctx context.Context, m map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+			orgId, _ := m["org_id"].(string)
+			envTypeFilter, _ := m["env_type"].(string)
+			renderCsv, _ := m["render_csv"].(bool)
+			staleDays := 30
+			if v, ok := m["stale_days"].(float64); ok && v >= 1 {
+				staleDays = int(v)
+			}
+			var appIdPattern *regexp.Regexp
+			if v, ok := m["app_id"].(string); ok {
+				var err error
+				if appIdPattern, err = regexp.CompilePOSIX(v); err != nil {
+					return nil, fmt.Errorf("invalid app_id  regex: %w", err)
+				}
+			}
+
+			hc, err := humanitec.NewHumanitecClientWithCurrentToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+			apps, appErrors, err := listAppsAndEnvs(ctx, hc, orgId, appIdPattern, envTypeFilter)
+			if err != nil {
+				return nil, err
+			}
+			sets, setsErr := fetchDeployedSets(ctx, hc, orgId, apps)
+			report := buildInventoryReport(apps, sets, staleDays, time.Now())
+
+			out := []mcp.CallToolResponseContent{
+				mcp.NewTextToolResponseContent("The inventory of Organization '%s' in JSON format: %s", orgId, internal.PrettyJson(report)),
+			}
+			if setsErr != nil {
+				out = append(out, mcp.NewTextToolResponseContent("Some deployment sets could not be fetched so the workloads and images are incomplete: %v", setsErr.Error()))
+			}
+			out = append(out, appErrorsResponse(appErrors)...)
+			if renderCsv {
+				if r, err := renderCsvAsTable(ctx, report.toCsv(), true, ""); err != nil {
+					out = append(out, mcp.NewTextToolResponseContent("Failed to render the inventory as a table: %v", err.Error()))
+				} else {
+					out = append(out, mcp.NewTextToolResponseContent("The inventory was rendered as a table: %s", r.Url))
+				}
+			}
+			return out, nil
+		},
+	}
+}
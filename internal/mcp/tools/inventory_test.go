@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"testing"
+	"time"
+
+	"github.com/humanitec/humanitec-go-autogen/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildInventoryReport(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	apps := map[string]appstate{
+		"shop": {Environments: map[string]envstate{
+			"development": {Type: "development", LastDeploymentTime: now.AddDate(0, 0, -1)},
+			"production":  {Type: "production", LastDeploymentTime: now.AddDate(0, 0, -90)},
+		}},
+		"blog": {Environments: map[string]envstate{
+			"development": {Type: "development"},
+		}},
+	}
+	set := func(image string) *client.SetResponse {
+		return &client.SetResponse{Modules: map[string]client.ModuleResponse{
+			"api": {Profile: "humanitec/default-module", Spec: map[string]interface{}{
+				"containers": map[string]interface{}{"main": map[string]interface{}{"image": image}},
+			}},
+		}}
+	}
+	report := buildInventoryReport(apps, []deployedSet{
+		{AppId: "shop", EnvId: "development", Set: set("api:2")},
+		{AppId: "shop", EnvId: "production", Set: set("api:1")},
+	}, 30, now)
+
+	assert.Equal(t, 2, report.Applications)
+	assert.Equal(t, 3, report.Environments)
+	assert.Equal(t, map[string]int{"development": 2, "production": 1}, report.EnvironmentsPerType)
+	assert.Len(t, report.StaleEnvironments, 2)
+	assert.Equal(t, "blog", report.StaleEnvironments[0].AppId)
+	assert.Nil(t, report.StaleEnvironments[0].LastDeploymentTime)
+	assert.Equal(t, "production", report.StaleEnvironments[1].EnvId)
+	assert.Equal(t, 1, report.Workloads)
+	assert.Equal(t, map[string]int{"humanitec/default-module": 1}, report.WorkloadsPerProfile)
+	assert.Equal(t, []inventoryImage{
+		{Image: "api:1", Workloads: []string{"shop/production/api"}},
+		{Image: "api:2", Workloads: []string{"shop/development/api"}},
+	}, report.Images)
+	assert.Contains(t, report.toCsv(), "images,api:1,shop/production/api\n")
+}
@@ -0,0 +1,159 @@
+package tools
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/url"
+	"sync"
+	"text/template"
+
+	"github.com/humanitec/canyon-cli/internal/config"
+)
+
+// externalLinksKey is the node data key the resolved external links are stored under for the tree and graph renders.
+const externalLinksKey = "external_links"
+
+// externalLink is a link to an external system shown on a render node.
+type externalLink struct {
+	Service string `json:"service"`
+	Url     string `json:"url"`
+}
+
+// linkNode is the data available to a link provider url template.
+type linkNode struct {
+	Id    string
+	Name  string
+	Class string
+	Data  map[string]interface{}
+	Value string
+}
+
+type linkProvider struct {
+	config.LinkProvider
+	tmpl *template.Template
+}
+
+// linkFuncs are the template functions for escaping node values in link provider urls. The values are inserted as is
+// otherwise, so a value with a space, &, #, or ? would change the path, query, or fragment of the link.
+var linkFuncs = template.FuncMap{
+	"pathEscape": func(v interface{}) string {
+		return url.PathEscape(fmt.Sprint(v))
+	},
+	"queryEscape": func(v interface{}) string {
+		return url.QueryEscape(fmt.Sprint(v))
+	},
+}
+
+// compileLinkProviders parses the url templates of the configured link providers.
+func compileLinkProviders(in []config.LinkProvider) ([]linkProvider, error) {
+	out := make([]linkProvider, 0, len(in))
+	for i, p := range in {
+		if p.Name == "" || p.Url == "" {
+			return nil, fmt.Errorf("link provider %d: name and url are required", i)
+		}
+		tmpl, err := template.New(p.Name).Funcs(linkFuncs).Option("missingkey=error").Parse(p.Url)
+		if err != nil {
+			return nil, fmt.Errorf("link provider '%s': invalid url template: %w", p.Name, err)
+		}
+		out = append(out, linkProvider{LinkProvider: p, tmpl: tmpl})
+	}
+	return out, nil
+}
+
+// linkProviders are the link providers from the config, loaded on first use.
+var linkProviders = sync.OnceValues(func() ([]linkProvider, error) {
+	c, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	return compileLinkProviders(c.LinkProviders)
+})
+
+// resolve returns the link for the node, or false if the provider does not apply to it.
+func (p linkProvider) resolve(node linkNode) (externalLink, bool, error) {
+	if p.Class != "" && p.Class != node.Class {
+		return externalLink{}, false, nil
+	}
+	if p.MetadataKey != "" {
+		v, ok := node.Data[p.MetadataKey]
+		if !ok || v == nil || fmt.Sprint(v) == "" {
+			return externalLink{}, false, nil
+		}
+		node.Value = fmt.Sprint(v)
+	}
+	buffer := new(bytes.Buffer)
+	if err := p.tmpl.Execute(buffer, node); err != nil {
+		return externalLink{}, false, fmt.Errorf("link provider '%s': failed to execute url template: %w", p.Name, err)
+	}
+	u, err := url.Parse(buffer.String())
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return externalLink{}, false, fmt.Errorf("link provider '%s': resolved an invalid url '%s', expected an http or https url", p.Name, buffer.String())
+	}
+	return externalLink{Service: p.Name, Url: u.String()}, true, nil
+}
+
+// resolveNodeLinks returns a copy of the node data with the external links from the providers that apply to the
+// node. Any external links in the input are replaced so that only configured, real links are shown. The node data comes
+// from the model, so a provider that fails for a node, for example because its template references a key the node
+// does not have, is logged and skipped for that node rather than failing the render.
+func resolveNodeLinks(providers []linkProvider, id, name, class string, data map[string]interface{}) map[string]interface{} {
+	out := maps.Clone(data)
+	if out == nil {
+		out = make(map[string]interface{})
+	}
+	delete(out, externalLinksKey)
+	links := make([]externalLink, 0)
+	for _, p := range providers {
+		link, ok, err := p.resolve(linkNode{Id: id, Name: name, Class: class, Data: out})
+		if err != nil {
+			slog.Warn("skipping external link", slog.String("node", id), slog.Any("err", err))
+		} else if ok {
+			links = append(links, link)
+		}
+	}
+	if len(links) > 0 {
+		out[externalLinksKey] = links
+	}
+	return out
+}
+
+// addTreeLinks returns a copy of the tree node and its children with the external links resolved into their data.
+func addTreeLinks(providers []linkProvider, node map[string]interface{}) map[string]interface{} {
+	out := maps.Clone(node)
+	name, _ := node["name"].(string)
+	class, _ := node["class"].(string)
+	data, _ := node["data"].(map[string]interface{})
+	out["data"] = resolveNodeLinks(providers, name, name, class, data)
+	if children, ok := node["children"].([]interface{}); ok {
+		outChildren := make([]interface{}, len(children))
+		for i, child := range children {
+			outChildren[i] = child
+			if c, ok := child.(map[string]interface{}); ok {
+				outChildren[i] = addTreeLinks(providers, c)
+			}
+		}
+		out["children"] = outChildren
+	}
+	return out
+}
+
+// addGraphLinks returns a copy of the graph nodes with the external links resolved into their data.
+func addGraphLinks(providers []linkProvider, nodes []interface{}) []interface{} {
+	out := make([]interface{}, len(nodes))
+	for i, node := range nodes {
+		out[i] = node
+		n, ok := node.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := n["id"].(string)
+		class, _ := n["class"].(string)
+		data, _ := n["data"].(map[string]interface{})
+		c := maps.Clone(n)
+		c["data"] = resolveNodeLinks(providers, id, id, class, data)
+		out[i] = c
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/humanitec/canyon-cli/internal/config"
+)
+
+func TestCompileLinkProviders_invalid(t *testing.T) {
+	_, err := compileLinkProviders([]config.LinkProvider{{Name: "x"}})
+	assert.EqualError(t, err, "link provider 0: name and url are required")
+	_, err = compileLinkProviders([]config.LinkProvider{{Name: "x", Url: "https://{{ .Id"}})
+	assert.ErrorContains(t, err, "link provider 'x': invalid url template")
+}
+
+func TestAddTreeLinks(t *testing.T) {
+	providers, err := compileLinkProviders([]config.LinkProvider{
+		{Name: "Console", Url: "https://console.example.com/{{ .Class }}/{{ .Id | pathEscape }}"},
+		{Name: "Datadog", Class: "workload", MetadataKey: "Service-Owner", Url: "https://app.datadoghq.com/teams/{{ .Value | pathEscape }}"},
+	})
+	assert.NoError(t, err)
+	in := map[string]interface{}{
+		"name": "my app", "class": "app",
+		"data": map[string]interface{}{"external_links": []interface{}{"https://made.up"}},
+		"children": []interface{}{
+			map[string]interface{}{"name": "web", "class": "workload", "data": map[string]interface{}{"Service-Owner": "team a"}},
+			map[string]interface{}{"name": "worker", "class": "workload"},
+		},
+	}
+	out := addTreeLinks(providers, in)
+	assert.Equal(t, map[string]interface{}{
+		"name": "my app", "class": "app",
+		"data": map[string]interface{}{"external_links": []externalLink{{Service: "Console", Url: "https://console.example.com/app/my%20app"}}},
+		"children": []interface{}{
+			map[string]interface{}{"name": "web", "class": "workload", "data": map[string]interface{}{
+				"Service-Owner": "team a",
+				"external_links": []externalLink{
+					{Service: "Console", Url: "https://console.example.com/workload/web"},
+					{Service: "Datadog", Url: "https://app.datadoghq.com/teams/team%20a"},
+				},
+			}},
+			map[string]interface{}{"name": "worker", "class": "workload", "data": map[string]interface{}{
+				"external_links": []externalLink{{Service: "Console", Url: "https://console.example.com/workload/worker"}},
+			}},
+		},
+	}, out)
+	// the input is not modified
+	assert.Equal(t, []interface{}{"https://made.up"}, in["data"].(map[string]interface{})["external_links"])
+}
+
+func TestAddGraphLinks(t *testing.T) {
+	providers, err := compileLinkProviders([]config.LinkProvider{
+		{Name: "Runbook", Class: "resource", Url: "https://runbooks.example.com/{{ .Data.type }}"},
+	})
+	assert.NoError(t, err)
+	out := addGraphLinks(providers, []interface{}{
+		map[string]interface{}{"id": "db", "class": "resource", "data": map[string]interface{}{"type": "postgres"}},
+		map[string]interface{}{"id": "app", "class": "app"},
+		// the template fails for a node without the type, so only that node is left without the link
+		map[string]interface{}{"id": "cache", "class": "resource", "data": map[string]interface{}{}},
+	})
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"id": "db", "class": "resource", "data": map[string]interface{}{
+			"type":           "postgres",
+			"external_links": []externalLink{{Service: "Runbook", Url: "https://runbooks.example.com/postgres"}},
+		}},
+		map[string]interface{}{"id": "app", "class": "app", "data": map[string]interface{}{}},
+		map[string]interface{}{"id": "cache", "class": "resource", "data": map[string]interface{}{}},
+	}, out)
+}
+
+func TestResolveNodeLinks_escaping(t *testing.T) {
+	providers, err := compileLinkProviders([]config.LinkProvider{
+		{Name: "Search", Url: "https://search.example.com/{{ .Name | pathEscape }}?q={{ .Value | queryEscape }}&class={{ .Class }}"},
+	})
+	assert.NoError(t, err)
+	link, ok, err := providers[0].resolve(linkNode{Name: "a&b c/d", Class: "app", Value: "x&y=1 #z?"})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "https://search.example.com/a&b%20c%2Fd?q=x%26y%3D1+%23z%3F&class=app", link.Url)
+	u, err := url.Parse(link.Url)
+	assert.NoError(t, err)
+	assert.Equal(t, "/a&b c/d", u.Path)
+	assert.Equal(t, url.Values{"q": {"x&y=1 #z?"}, "class": {"app"}}, u.Query())
+	assert.Empty(t, u.Fragment)
+}
+
+func TestResolveNodeLinks_rejects_non_http_urls(t *testing.T) {
+	providers, err := compileLinkProviders([]config.LinkProvider{{Name: "Bad", Url: "javascript:{{ .Id }}"}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{}, resolveNodeLinks(providers, "x", "x", "app", nil))
+	_, _, err = providers[0].resolve(linkNode{Id: "x"})
+	assert.EqualError(t, err, "link provider 'Bad': resolved an invalid url 'javascript:x', expected an http or https url")
+}
@@ -0,0 +1,209 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/humanitec/humanitec-go-autogen/client"
+
+	"github.com/humanitec/canyon-cli/internal/clients/humanitec"
+	"github.com/humanitec/canyon-cli/internal/mcp"
+	"github.com/humanitec/canyon-cli/internal/ref"
+)
+
+const (
+	defaultLogTail     = 100
+	maxLogTail         = 1000
+	defaultLogMaxChars = 8000
+	maxLogLineChars    = 500
+)
+
+var logLevels = []string{"debug", "info", "warn", "error", "fatal"}
+
+var logLevelPattern = regexp.MustCompile(`(?i)\b(debug|info|warn|warning|error|err|fatal|panic|critical)\b`)
+
+// logLevelIndex returns the severity index of the entry, preferring the level reported by the platform and falling
+// back to the first level-like word in the payload. Unknown levels are treated as info.
+func logLevelIndex(entry client.OutputEntryResponse) int {
+	for _, candidate := range []string{entry.Level, logLevelPattern.FindString(entry.Payload)} {
+		switch strings.ToLower(candidate) {
+		case "debug":
+			return 0
+		case "info":
+			return 1
+		case "warn", "warning":
+			return 2
+		case "error", "err":
+			return 3
+		case "fatal", "panic", "critical":
+			return 4
+		}
+	}
+	return 1
+}
+
+// selectLogEntries returns the most recent tail entries at or above the minimum level in chronological order. The
+// entries are fetched newest first.
+func selectLogEntries(fetched []client.OutputEntryResponse, minLevel int, tail int) []client.OutputEntryResponse {
+	entries := make([]client.OutputEntryResponse, 0)
+	for _, e := range fetched {
+		if minLevel == 0 || logLevelIndex(e) >= minLevel {
+			entries = append(entries, e)
+		}
+	}
+	entries = entries[:min(tail, len(entries))]
+	slices.Reverse(entries)
+	return entries
+}
+
+// condenseLogEntries formats log entries as lines while keeping the output within maxChars. Consecutive duplicate
+// payloads are collapsed, very long lines are cut, and when the budget is exceeded the oldest lines are dropped first
+// since the most recent lines are usually the most relevant.
+func condenseLogEntries(entries []client.OutputEntryResponse, maxChars int) (lines []string, omitted int) {
+	for i := 0; i < len(entries); i++ {
+		e := entries[i]
+		repeats := 0
+		for i+1 < len(entries) && entries[i+1].Payload == e.Payload && entries[i+1].ContainerId == e.ContainerId {
+			repeats++
+			i++
+		}
+		payload := strings.TrimRight(e.Payload, "\n")
+		if len(payload) > maxLogLineChars {
+			// cut on a rune boundary so that the line stays valid UTF-8
+			cut := maxLogLineChars
+			for cut > 0 && !utf8.RuneStart(payload[cut]) {
+				cut--
+			}
+			payload = fmt.Sprintf("%s...[%d chars truncated]", payload[:cut], len(payload)-cut)
+		}
+		line := fmt.Sprintf("%s [%s] %s", e.Timestamp, e.ContainerId, payload)
+		if repeats > 0 {
+			line += fmt.Sprintf(" (repeated %d more times)", repeats)
+		}
+		lines = append(lines, line)
+	}
+
+	total := 0
+	for i := len(lines) - 1; i >= 0; i-- {
+		total += len(lines[i]) + 1
+		if total > maxChars {
+			return lines[i+1:], i + 1
+		}
+	}
+	return lines, 0
+}
+
+func NewGetWorkloadLogs() mcp.Tool {
+	return mcp.Tool{
+		Name: "get_humanitec_workload_logs",
+		Description: `This tool retrieves the runtime container logs of a workload deployed in a Humanitec Environment.
+The logs can be filtered by container, time window, and minimum log level. The most recent lines are returned first when the output must be truncated to fit.
+This is usually the first thing to check after a deployment fails or a workload is not healthy.`,
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"org_id":        map[string]interface{}{"type": "string", "description": "The Humanitec Organization (org) ID to work with."},
+				"app_id":        map[string]interface{}{"type": "string", "description": "The Humanitec Application (app) ID to work with."},
+				"env_id":        map[string]interface{}{"type": "string", "description": "The Humanitec Environment (env) ID to work with."},
+				"workload_id":   map[string]interface{}{"type": "string", "description": "The workload ID, this is the module name in the deployment set."},
+				"container_id":  map[string]interface{}{"type": "string", "description": "Optional container within the workload to fetch logs for"},
+				"deployment_id": map[string]interface{}{"type": "string", "description": "Optional deployment ID to restrict the logs to"},
+				"since":         map[string]interface{}{"type": "string", "description": "Optional duration to look back from now, for example 15m or 2h"},
+				"from":          map[string]interface{}{"type": "string", "description": "Optional RFC3339 start of the time window, ignored if since is set"},
+				"to":            map[string]interface{}{"type": "string", "description": "Optional RFC3339 end of the time window"},
+				"tail":          map[string]interface{}{"type": "integer", "description": "The maximum number of most recent log lines to fetch, defaults to 100", "minimum": 1, "maximum": maxLogTail},
+				"level":         map[string]interface{}{"type": "string", "enum": logLevels, "description": "Optional minimum log level to include"},
+				"max_chars":     map[string]interface{}{"type": "integer", "description": "The maximum size of the returned logs in characters, defaults to 8000", "minimum": 500},
+			},
+			"required":             []string{"org_id", "app_id", "env_id", "workload_id"},
+			"additionalProperties": false,
+		},
+		Callable: func(ctx context.Context, m map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+			orgId, _ := m["org_id"].(string)
+			appId, _ := m["app_id"].(string)
+			envId, _ := m["env_id"].(string)
+			workloadId, _ := m["workload_id"].(string)
+
+			tail := defaultLogTail
+			if v, ok := m["tail"].(float64); ok && v >= 1 {
+				tail = min(int(v), maxLogTail)
+			}
+			maxChars := defaultLogMaxChars
+			if v, ok := m["max_chars"].(float64); ok && v >= 500 {
+				maxChars = int(v)
+			}
+			minLevel := 0
+			if v, _ := m["level"].(string); v != "" {
+				if minLevel = slices.Index(logLevels, strings.ToLower(v)); minLevel < 0 {
+					return nil, fmt.Errorf("invalid level '%s', expected one of %s", v, strings.Join(logLevels, ", "))
+				}
+			}
+
+			params := &client.GetOrgsOrgIdAppsAppIdEnvsEnvIdLogsParams{
+				WorkloadId: &workloadId,
+				Asc:        ref.Ref(false),
+			}
+			// When filtering by level, over-fetch so that there are still enough lines left after filtering.
+			fetch := tail
+			if minLevel > 0 {
+				fetch = maxLogTail
+			}
+			params.Limit = ref.Ref(strconv.Itoa(fetch))
+			if v, _ := m["container_id"].(string); v != "" {
+				params.ContainerId = &v
+			}
+			if v, _ := m["deployment_id"].(string); v != "" {
+				params.DeploymentId = &v
+			}
+			if v, _ := m["since"].(string); v != "" {
+				d, err := time.ParseDuration(v)
+				if err != nil {
+					return nil, fmt.Errorf("invalid since duration: %w", err)
+				}
+				params.TimestampFrom = ref.Ref(time.Now().Add(-d).UTC().Format(time.RFC3339))
+			} else if v, _ := m["from"].(string); v != "" {
+				if _, err := time.Parse(time.RFC3339, v); err != nil {
+					return nil, fmt.Errorf("invalid from timestamp: %w", err)
+				}
+				params.TimestampFrom = &v
+			}
+			if v, _ := m["to"].(string); v != "" {
+				if _, err := time.Parse(time.RFC3339, v); err != nil {
+					return nil, fmt.Errorf("invalid to timestamp: %w", err)
+				}
+				params.TimestampTo = &v
+			}
+
+			hc, err := humanitec.NewHumanitecClientWithCurrentToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+			r, err := humanitec.CheckResponse(func() (*client.GetOrgsOrgIdAppsAppIdEnvsEnvIdLogsResponse, error) {
+				return hc.GetOrgsOrgIdAppsAppIdEnvsEnvIdLogsWithResponse(ctx, orgId, appId, envId, params)
+			}).AndStatusCodeEq(http.StatusOK).RespAndError()
+			if err != nil {
+				return nil, err
+			}
+
+			entries := selectLogEntries(ref.Deref(r.JSON200, nil), minLevel, tail)
+
+			if len(entries) == 0 {
+				return []mcp.CallToolResponseContent{mcp.NewTextToolResponseContent("No log lines were found for workload '%s' in Environment '%s' with the given filters.", workloadId, envId)}, nil
+			}
+
+			lines, omitted := condenseLogEntries(entries, maxChars)
+			header := fmt.Sprintf("The last %d log lines of workload '%s' in Environment '%s'", len(entries), workloadId, envId)
+			if omitted > 0 {
+				header += fmt.Sprintf(" (%d older lines omitted to fit the size limit)", omitted)
+			}
+			return []mcp.CallToolResponseContent{mcp.NewTextToolResponseContent("%s:\n%s", header, strings.Join(lines, "\n"))}, nil
+		},
+	}
+}
@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/humanitec/humanitec-go-autogen/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogLevelIndex(t *testing.T) {
+	for _, tc := range []struct {
+		entry client.OutputEntryResponse
+		level int
+	}{
+		{client.OutputEntryResponse{Level: "ERROR", Payload: "debug details"}, 3},
+		{client.OutputEntryResponse{Payload: "2024-01-01 WARNING disk almost full"}, 2},
+		{client.OutputEntryResponse{Payload: "panic: runtime error"}, 4},
+		{client.OutputEntryResponse{Payload: "level=debug msg=tick"}, 0},
+		{client.OutputEntryResponse{Payload: "listening on :8080"}, 1},
+		{client.OutputEntryResponse{Payload: "errors_total=0"}, 1},
+	} {
+		assert.Equal(t, tc.level, logLevelIndex(tc.entry), tc.entry.Payload)
+	}
+}
+
+func TestCondenseLogEntries(t *testing.T) {
+	entries := []client.OutputEntryResponse{
+		{Timestamp: "t1", ContainerId: "main", Payload: "starting\n"},
+		{Timestamp: "t2", ContainerId: "main", Payload: "retrying"},
+		{Timestamp: "t3", ContainerId: "main", Payload: "retrying"},
+		{Timestamp: "t4", ContainerId: "main", Payload: "retrying"},
+		{Timestamp: "t5", ContainerId: "sidecar", Payload: "retrying"},
+	}
+	lines, omitted := condenseLogEntries(entries, 1000)
+	assert.Equal(t, []string{
+		"t1 [main] starting",
+		"t2 [main] retrying (repeated 2 more times)",
+		"t5 [sidecar] retrying",
+	}, lines)
+	assert.Equal(t, 0, omitted)
+
+	// the oldest lines are dropped first to fit the budget
+	lines, omitted = condenseLogEntries(entries, 70)
+	assert.Equal(t, []string{"t2 [main] retrying (repeated 2 more times)", "t5 [sidecar] retrying"}, lines)
+	assert.Equal(t, 1, omitted)
+}
+
+func TestCondenseLogEntries_truncatesOnRuneBoundary(t *testing.T) {
+	// the multi-byte rune straddles the cut
+	payload := strings.Repeat("a", maxLogLineChars-1) + "é" + strings.Repeat("b", 10)
+	lines, _ := condenseLogEntries([]client.OutputEntryResponse{{Timestamp: "t1", ContainerId: "main", Payload: payload}}, 10000)
+	if assert.Len(t, lines, 1) {
+		assert.True(t, utf8.ValidString(lines[0]))
+		assert.Equal(t, "t1 [main] "+strings.Repeat("a", maxLogLineChars-1)+"...[12 chars truncated]", lines[0])
+	}
+}
+
+func TestSelectLogEntries(t *testing.T) {
+	fetched := []client.OutputEntryResponse{
+		{Timestamp: "t4", Payload: "ERROR failed"},
+		{Timestamp: "t3", Payload: "debug tick"},
+		{Timestamp: "t2", Level: "warn", Payload: "slow"},
+		{Timestamp: "t1", Payload: "ERROR first"},
+	}
+	timestamps := func(entries []client.OutputEntryResponse) []string {
+		out := make([]string, 0, len(entries))
+		for _, e := range entries {
+			out = append(out, e.Timestamp)
+		}
+		return out
+	}
+	assert.Equal(t, []string{"t1", "t2", "t3", "t4"}, timestamps(selectLogEntries(fetched, 0, 10)))
+	assert.Equal(t, []string{"t1", "t2", "t4"}, timestamps(selectLogEntries(fetched, 2, 10)))
+	assert.Equal(t, []string{"t2", "t4"}, timestamps(selectLogEntries(fetched, 2, 2)))
+	assert.Empty(t, selectLogEntries(nil, 3, 10))
+}
@@ -0,0 +1,424 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/humanitec/humanitec-go-autogen/client"
+
+	"github.com/humanitec/canyon-cli/internal"
+	"github.com/humanitec/canyon-cli/internal/clients/humanitec"
+	"github.com/humanitec/canyon-cli/internal/config"
+	"github.com/humanitec/canyon-cli/internal/mcp"
+	"github.com/humanitec/canyon-cli/internal/ref"
+)
+
+const maxMetadataExamples = 3
+
+// metadataEntry is a single metadata key and value found on a workload or resource.
+type metadataEntry struct {
+	Kind   string `json:"kind"`
+	AppId  string `json:"appId,omitempty"`
+	EnvId  string `json:"envId,omitempty"`
+	Object string `json:"object"`
+	Source string `json:"source"`
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+}
+
+// appendMetadataMap appends the string entries of a metadata map such as annotations or labels.
+func appendMetadataMap(out []metadataEntry, template metadataEntry, raw interface{}) []metadataEntry {
+	m, _ := raw.(map[string]interface{})
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		e := template
+		e.Key = k
+		e.Value = fmt.Sprint(m[k])
+		out = append(out, e)
+	}
+	return out
+}
+
+// workloadMetadata returns the annotations and labels of every workload in the deployed set. Workloads without any
+// metadata are returned as a single entry with an empty key so that they can still be found by existence checks.
+func workloadMetadata(ds deployedSet) []metadataEntry {
+	out := make([]metadataEntry, 0)
+	names := make([]string, 0, len(ds.Set.Modules))
+	for name := range ds.Set.Modules {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		spec := ds.Set.Modules[name].Spec
+		before := len(out)
+		for _, source := range []string{"annotations", "labels"} {
+			out = appendMetadataMap(out, metadataEntry{Kind: "workload", AppId: ds.AppId, EnvId: ds.EnvId, Object: name, Source: source}, spec[source])
+		}
+		if svc, ok := spec["service"].(map[string]interface{}); ok {
+			out = appendMetadataMap(out, metadataEntry{Kind: "workload", AppId: ds.AppId, EnvId: ds.EnvId, Object: name, Source: "service annotations"}, svc["annotations"])
+		}
+		if len(out) == before {
+			out = append(out, metadataEntry{Kind: "workload", AppId: ds.AppId, EnvId: ds.EnvId, Object: name})
+		}
+	}
+	return out
+}
+
+// setResourceMetadata returns the annotations, labels, and tags in the params of the shared resources and of the
+// workload externals of the deployed set. The objects are named by their resource reference, such as
+// shared.db or modules.api.externals.queue. Resources without any metadata are returned as a single entry with an
+// empty key so that they can still be found by existence checks.
+func setResourceMetadata(ds deployedSet) []metadataEntry {
+	out := make([]metadataEntry, 0)
+	appendResources := func(prefix string, resources map[string]interface{}) {
+		ids := make([]string, 0, len(resources))
+		for id := range resources {
+			ids = append(ids, id)
+		}
+		slices.Sort(ids)
+		for _, id := range ids {
+			res, _ := resources[id].(map[string]interface{})
+			params, _ := res["params"].(map[string]interface{})
+			before := len(out)
+			for _, source := range []string{"annotations", "labels", "tags"} {
+				out = appendMetadataMap(out, metadataEntry{Kind: "resource", AppId: ds.AppId, EnvId: ds.EnvId, Object: prefix + id, Source: "params " + source}, params[source])
+			}
+			if len(out) == before {
+				out = append(out, metadataEntry{Kind: "resource", AppId: ds.AppId, EnvId: ds.EnvId, Object: prefix + id})
+			}
+		}
+	}
+	appendResources("shared.", ds.Set.Shared)
+	names := make([]string, 0, len(ds.Set.Modules))
+	for name := range ds.Set.Modules {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		appendResources("modules."+name+".externals.", ds.Set.Modules[name].Externals)
+	}
+	return out
+}
+
+// resourceDefinitionMetadata returns the annotations, labels, and tags declared in the driver inputs of the resource
+// definition. These typically end up on the provisioned cloud resources. Definitions without any metadata are returned
+// as a single entry with an empty key so that they can still be found by existence checks.
+func resourceDefinitionMetadata(def client.ResourceDefinitionResponse) []metadataEntry {
+	out := make([]metadataEntry, 0)
+	if def.DriverInputs != nil {
+		values := ref.Deref(def.DriverInputs.Values, nil)
+		for _, source := range []string{"annotations", "labels", "tags", "metadata"} {
+			out = appendMetadataMap(out, metadataEntry{Kind: "resource", Object: def.Id, Source: source}, values[source])
+		}
+	}
+	if len(out) == 0 {
+		out = append(out, metadataEntry{Kind: "resource", Object: def.Id})
+	}
+	return out
+}
+
+// collectOrgMetadata gathers the metadata entries of the workloads, shared resources, and workload externals in the
+// deployed sets of every environment and from the resource definitions of the org. The optional appIdPattern and envTypeFilter restrict the environments scanned. Partial
+// failures are logged and skipped so that one broken environment does not hide the metadata of the rest of the org, and
+// the applications that could not be fetched are returned by app id.
+func collectOrgMetadata(ctx context.Context, hc *humanitec.WrappedHumanitecClientImpl, orgId string, appIdPattern *regexp.Regexp, envTypeFilter string) ([]metadataEntry, map[string]string, error) {
+	apps, appErrors, err := listAppsAndEnvs(ctx, hc, orgId, appIdPattern, envTypeFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+	sets, err := fetchDeployedSets(ctx, hc, orgId, apps)
+	if err != nil {
+		slog.Warn("failed to fetch some deployment sets", slog.Any("err", err))
+	}
+	out := make([]metadataEntry, 0)
+	for _, ds := range sets {
+		out = append(out, workloadMetadata(ds)...)
+		out = append(out, setResourceMetadata(ds)...)
+	}
+	defs, err := listResourceDefinitions(ctx, hc, orgId, &client.ListResourceDefinitionsParams{})
+	if err != nil {
+		slog.Warn("failed to list resource definitions", slog.Any("err", err))
+	}
+	for _, def := range defs {
+		out = append(out, resourceDefinitionMetadata(def)...)
+	}
+	return out, appErrors, nil
+}
+
+type metadataKeySummary struct {
+	Key         string   `json:"key"`
+	Description string   `json:"description,omitempty"`
+	Count       int      `json:"count"`
+	Kinds       []string `json:"kinds,omitempty"`
+	Sources     []string `json:"sources,omitempty"`
+	Examples    []string `json:"examples,omitempty"`
+}
+
+// summarizeMetadataKeys counts the usage of each key and merges in the descriptions published in the config. Keys
+// published in the config but not in use are included with a zero count.
+func summarizeMetadataKeys(entries []metadataEntry, published []config.MetadataKey) []metadataKeySummary {
+	byKey := make(map[string]*metadataKeySummary)
+	get := func(key string) *metadataKeySummary {
+		if s, ok := byKey[key]; ok {
+			return s
+		}
+		byKey[key] = &metadataKeySummary{Key: key}
+		return byKey[key]
+	}
+	for _, e := range entries {
+		if e.Key == "" {
+			continue
+		}
+		s := get(e.Key)
+		s.Count++
+		if !slices.Contains(s.Kinds, e.Kind) {
+			s.Kinds = append(s.Kinds, e.Kind)
+		}
+		if !slices.Contains(s.Sources, e.Source) {
+			s.Sources = append(s.Sources, e.Source)
+		}
+		if len(s.Examples) < maxMetadataExamples && e.Value != "" && !slices.Contains(s.Examples, e.Value) {
+			s.Examples = append(s.Examples, e.Value)
+		}
+	}
+	for _, p := range published {
+		get(p.Key).Description = p.Description
+	}
+	out := make([]metadataKeySummary, 0, len(byKey))
+	for _, s := range byKey {
+		out = append(out, *s)
+	}
+	slices.SortFunc(out, func(a, b metadataKeySummary) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+	return out
+}
+
+func NewListOrganizationMetadataKeys() mcp.Tool {
+	return mcp.Tool{
+		Name: "list_organization_metadata_keys",
+		Description: `This tool lists the known metadata keys for an organization along with how often each is used and some example values.
+The keys are derived from the annotations and labels of the workloads and the annotations, labels, and tags in the params of the shared resources and workload externals in the deployment sets of every environment, and from the annotations, labels, and tags of the resource definitions.
+Descriptions published by the platform team are included where available.`,
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"org_id": map[string]interface{}{"type": "string", "description": "The Humanitec Organization (org) ID to work with."},
+			},
+			"required": []string{"org_id"},
+		},
+		Callable: func(ctx context.Context, m map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+			orgId, _ := m["org_id"].(string)
+			cfg, err := config.Load()
+			if err != nil {
+				return nil, err
+			}
+			hc, err := humanitec.NewHumanitecClientWithCurrentToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+			entries, appErrors, err := collectOrgMetadata(ctx, hc, orgId, nil, "")
+			if err != nil {
+				return nil, err
+			}
+			raw := internal.PrettyJson(summarizeMetadataKeys(entries, cfg.MetadataKeys))
+			return append([]mcp.CallToolResponseContent{
+				mcp.NewTextToolResponseContent("The following workload and resource metadata keys are known for this org in JSON format: %s", string(raw)),
+			}, appErrorsResponse(appErrors)...), nil
+		},
+	}
+}
+
+// metadataFilter is a single condition on the metadata of a workload or resource. An empty key matches any key.
+type metadataFilter struct {
+	Key        string
+	Value      *string
+	ValueRegex *regexp.Regexp
+	Exists     *bool
+}
+
+func (f metadataFilter) matches(metadata map[string]string) bool {
+	if f.Key != "" {
+		v, ok := metadata[f.Key]
+		if f.Exists != nil && *f.Exists != ok {
+			return false
+		}
+		return f.matchesValue(v, ok)
+	}
+	for _, v := range metadata {
+		if f.matchesValue(v, true) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f metadataFilter) matchesValue(v string, present bool) bool {
+	if f.Value != nil && (!present || v != *f.Value) {
+		return false
+	}
+	if f.ValueRegex != nil && (!present || !f.ValueRegex.MatchString(v)) {
+		return false
+	}
+	return present || (f.Exists != nil && !*f.Exists)
+}
+
+func parseMetadataFilters(raw []interface{}) ([]metadataFilter, error) {
+	out := make([]metadataFilter, 0, len(raw))
+	for i, r := range raw {
+		m, ok := r.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("filter %d is not an object", i)
+		}
+		f := metadataFilter{}
+		f.Key, _ = m["key"].(string)
+		if v, ok := m["value"].(string); ok {
+			f.Value = &v
+		}
+		if v, ok := m["value_regex"].(string); ok {
+			re, err := regexp.Compile(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value_regex in filter %d: %w", i, err)
+			}
+			f.ValueRegex = re
+		}
+		if v, ok := m["exists"].(bool); ok {
+			f.Exists = &v
+		}
+		if f.Key == "" && f.Value == nil && f.ValueRegex == nil {
+			return nil, fmt.Errorf("filter %d must set at least one of key, value, or value_regex", i)
+		} else if f.Key == "" && f.Exists != nil {
+			return nil, fmt.Errorf("filter %d must set a key to check whether it exists", i)
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+type metadataObject struct {
+	Kind     string            `json:"kind"`
+	AppId    string            `json:"appId,omitempty"`
+	EnvId    string            `json:"envId,omitempty"`
+	Object   string            `json:"object"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// searchMetadataObjects groups the metadata entries by the workload or resource they belong to and returns the
+// objects that match all of the filters, or any of them when matchAny is set.
+func searchMetadataObjects(entries []metadataEntry, filters []metadataFilter, matchAny bool) []metadataObject {
+	type objectKey struct{ kind, appId, envId, object string }
+	objects := make(map[objectKey]*metadataObject)
+	order := make([]objectKey, 0)
+	for _, e := range entries {
+		k := objectKey{e.Kind, e.AppId, e.EnvId, e.Object}
+		if _, ok := objects[k]; !ok {
+			objects[k] = &metadataObject{Kind: e.Kind, AppId: e.AppId, EnvId: e.EnvId, Object: e.Object, Metadata: make(map[string]string)}
+			order = append(order, k)
+		}
+		if e.Key != "" {
+			objects[k].Metadata[e.Key] = e.Value
+		}
+	}
+	out := make([]metadataObject, 0)
+	for _, k := range order {
+		o := objects[k]
+		matched := !matchAny
+		for _, f := range filters {
+			if f.matches(o.Metadata) == matchAny {
+				matched = matchAny
+				break
+			}
+		}
+		if matched {
+			out = append(out, *o)
+		}
+	}
+	return out
+}
+
+func NewSearchMetadata() mcp.Tool {
+	return mcp.Tool{
+		Name: "search_humanitec_metadata",
+		Description: `This tool searches the workloads and resources across all applications and environments in an organization by their metadata, such as annotations, labels, and tags.
+Use it to answer questions like "which workloads are owned by team X" or "find everything tagged with this AWS ARN". Use list_organization_metadata_keys first to discover the available keys.
+The workloads, shared resources, and workload externals are taken from the deployment sets of every environment, and the resources also include the resource definitions of the organization.
+Each filter can check a key for an exact value, a value regex, or just whether the key exists. A filter without a key matches the value against any key.`,
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"org_id":   map[string]interface{}{"type": "string", "description": "The Humanitec Organization (org) ID to work with."},
+				"app_id":   map[string]interface{}{"type": "string", "description": "Optional regex pattern to filter for app id"},
+				"env_type": map[string]interface{}{"type": "string", "description": "Optional filter for a specific environment type"},
+				"kind":     map[string]interface{}{"type": "string", "enum": []string{"workload", "resource"}, "description": "Optionally only return workloads or resources"},
+				"filters": map[string]interface{}{"type": "array", "description": "The metadata filters to apply", "minItems": 1, "items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"key":         map[string]interface{}{"type": "string", "description": "The metadata key, if empty the value is matched against any key"},
+						"value":       map[string]interface{}{"type": "string", "description": "The exact value the key must have"},
+						"value_regex": map[string]interface{}{"type": "string", "description": "A regex that the value must match"},
+						"exists":      map[string]interface{}{"type": "boolean", "description": "Whether the key must exist or must not exist, requires a key"},
+					},
+					"additionalProperties": false,
+				}},
+				"match": map[string]interface{}{"type": "string", "enum": []string{"all", "any"}, "description": "Whether all or any of the filters must match, defaults to all"},
+			},
+			"required":             []string{"org_id", "filters"},
+			"additionalProperties": false,
+		},
+		Callable: func(ctx context.Context, m map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+			orgId, _ := m["org_id"].(string)
+			var appIdPattern *regexp.Regexp
+			if v, ok := m["app_id"].(string); ok {
+				var err error
+				if appIdPattern, err = regexp.CompilePOSIX(v); err != nil {
+					return nil, fmt.Errorf("invalid app_id regex: %w", err)
+				}
+			}
+			envTypeFilter, _ := m["env_type"].(string)
+			kind, _ := m["kind"].(string)
+			matchAny := m["match"] == "any"
+			rawFilters, _ := m["filters"].([]interface{})
+			filters, err := parseMetadataFilters(rawFilters)
+			if err != nil {
+				return nil, err
+			}
+			if len(filters) == 0 {
+				return nil, fmt.Errorf("at least one filter is required")
+			}
+
+			hc, err := humanitec.NewHumanitecClientWithCurrentToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+			entries, appErrors, err := collectOrgMetadata(ctx, hc, orgId, appIdPattern, envTypeFilter)
+			if err != nil {
+				return nil, err
+			}
+			if kind != "" {
+				entries = slices.DeleteFunc(entries, func(e metadataEntry) bool {
+					return e.Kind != kind
+				})
+			}
+			out := searchMetadataObjects(entries, filters, matchAny)
+			if len(out) == 0 {
+				return append([]mcp.CallToolResponseContent{
+					mcp.NewTextToolResponseContent("No workloads or resources in Organization '%s' match the metadata filters.", orgId),
+				}, appErrorsResponse(appErrors)...), nil
+			}
+			return append([]mcp.CallToolResponseContent{
+				mcp.NewTextToolResponseContent("%d workloads and resources in Organization '%s' match the metadata filters, in JSON format: %s", len(out), orgId, internal.PrettyJson(out)),
+			}, appErrorsResponse(appErrors)...), nil
+		},
+	}
+}
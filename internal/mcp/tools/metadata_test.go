@@ -0,0 +1,160 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/humanitec/humanitec-go-autogen/client"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/humanitec/canyon-cli/internal/config"
+	"github.com/humanitec/canyon-cli/internal/ref"
+)
+
+func TestWorkloadMetadata(t *testing.T) {
+	entries := workloadMetadata(deployedSet{AppId: "shop", EnvId: "production", Set: &client.SetResponse{Modules: map[string]client.ModuleResponse{
+		"worker": {Spec: map[string]interface{}{}},
+		"api": {Spec: map[string]interface{}{
+			"annotations": map[string]interface{}{"team": "payments", "cost-center": 42},
+			"labels":      map[string]interface{}{"tier": "backend"},
+			"service":     map[string]interface{}{"annotations": map[string]interface{}{"dns": "api.example.com"}},
+		}},
+	}}})
+	template := metadataEntry{Kind: "workload", AppId: "shop", EnvId: "production"}
+	with := func(object, source, key, value string) metadataEntry {
+		e := template
+		e.Object, e.Source, e.Key, e.Value = object, source, key, value
+		return e
+	}
+	assert.Equal(t, []metadataEntry{
+		with("api", "annotations", "cost-center", "42"),
+		with("api", "annotations", "team", "payments"),
+		with("api", "labels", "tier", "backend"),
+		with("api", "service annotations", "dns", "api.example.com"),
+		// workloads without metadata are kept for existence checks
+		with("worker", "", "", ""),
+	}, entries)
+}
+
+func TestSetResourceMetadata(t *testing.T) {
+	entries := setResourceMetadata(deployedSet{AppId: "shop", EnvId: "production", Set: &client.SetResponse{
+		Shared: map[string]interface{}{
+			"dns": map[string]interface{}{"type": "dns"},
+			"db": map[string]interface{}{"type": "postgres", "params": map[string]interface{}{
+				"tags": map[string]interface{}{"team": "data"},
+			}},
+		},
+		Modules: map[string]client.ModuleResponse{
+			"api": {Externals: map[string]interface{}{
+				"queue": map[string]interface{}{"type": "amqp", "params": map[string]interface{}{
+					"annotations": map[string]interface{}{"owner": "payments"},
+					"labels":      map[string]interface{}{"tier": "messaging"},
+				}},
+			}},
+		},
+	}})
+	template := metadataEntry{Kind: "resource", AppId: "shop", EnvId: "production"}
+	with := func(object, source, key, value string) metadataEntry {
+		e := template
+		e.Object, e.Source, e.Key, e.Value = object, source, key, value
+		return e
+	}
+	assert.Equal(t, []metadataEntry{
+		with("shared.db", "params tags", "team", "data"),
+		// resources without metadata are kept for existence checks
+		with("shared.dns", "", "", ""),
+		with("modules.api.externals.queue", "params annotations", "owner", "payments"),
+		with("modules.api.externals.queue", "params labels", "tier", "messaging"),
+	}, entries)
+}
+
+func TestResourceDefinitionMetadata(t *testing.T) {
+	assert.Equal(t, []metadataEntry{
+		{Kind: "resource", Object: "rds", Source: "tags", Key: "team", Value: "data"},
+	}, resourceDefinitionMetadata(client.ResourceDefinitionResponse{Id: "rds", DriverInputs: &client.ValuesSecretsRefsResponse{
+		Values: &map[string]interface{}{"tags": map[string]interface{}{"team": "data"}},
+	}}))
+	// definitions without metadata are kept for existence checks
+	assert.Equal(t, []metadataEntry{{Kind: "resource", Object: "dns"}}, resourceDefinitionMetadata(client.ResourceDefinitionResponse{Id: "dns"}))
+	assert.Equal(t, []metadataEntry{{Kind: "resource", Object: "s3"}}, resourceDefinitionMetadata(client.ResourceDefinitionResponse{Id: "s3", DriverInputs: &client.ValuesSecretsRefsResponse{}}))
+
+	missing := searchMetadataObjects(resourceDefinitionMetadata(client.ResourceDefinitionResponse{Id: "dns"}), []metadataFilter{{Key: "team", Exists: ref.Ref(false)}}, false)
+	if assert.Len(t, missing, 1) {
+		assert.Equal(t, "dns", missing[0].Object)
+	}
+}
+
+func TestSummarizeMetadataKeys(t *testing.T) {
+	entries := []metadataEntry{
+		{Kind: "workload", Object: "api", Source: "annotations", Key: "team", Value: "payments"},
+		{Kind: "workload", Object: "web", Source: "annotations", Key: "team", Value: "payments"},
+		{Kind: "resource", Object: "db", Source: "tags", Key: "team", Value: "data"},
+		{Kind: "workload", Object: "api", Source: "labels", Key: "tier", Value: "backend"},
+		{Kind: "workload", Object: "worker"},
+	}
+	assert.Equal(t, []metadataKeySummary{
+		{Key: "team", Description: "The owning team", Count: 3, Kinds: []string{"workload", "resource"}, Sources: []string{"annotations", "tags"}, Examples: []string{"payments", "data"}},
+		{Key: "tier", Count: 1, Kinds: []string{"workload"}, Sources: []string{"labels"}, Examples: []string{"backend"}},
+		{Key: "cost-center", Description: "Published but unused"},
+	}, summarizeMetadataKeys(entries, []config.MetadataKey{
+		{Key: "team", Description: "The owning team"},
+		{Key: "cost-center", Description: "Published but unused"},
+	}))
+}
+
+func TestMetadataFilters(t *testing.T) {
+	entries := []metadataEntry{
+		{Kind: "workload", AppId: "shop", EnvId: "production", Object: "api", Source: "annotations", Key: "team", Value: "payments"},
+		{Kind: "workload", AppId: "shop", EnvId: "production", Object: "api", Source: "labels", Key: "tier", Value: "backend"},
+		{Kind: "workload", AppId: "shop", EnvId: "production", Object: "worker"},
+		{Kind: "resource", Object: "db", Source: "tags", Key: "arn", Value: "arn:aws:rds:eu-west-1:123:db/orders"},
+		{Kind: "resource", Object: "queue", Source: "tags", Key: "team", Value: "platform"},
+	}
+	for _, tc := range []struct {
+		name     string
+		filters  []interface{}
+		matchAny bool
+		objects  []string
+	}{
+		{name: "key exists", filters: []interface{}{map[string]interface{}{"key": "team"}}, objects: []string{"api", "queue"}},
+		{name: "key exists explicitly", filters: []interface{}{map[string]interface{}{"key": "team", "exists": true}}, objects: []string{"api", "queue"}},
+		{name: "key does not exist", filters: []interface{}{map[string]interface{}{"key": "team", "exists": false}}, objects: []string{"worker", "db"}},
+		{name: "equality", filters: []interface{}{map[string]interface{}{"key": "team", "value": "payments"}}, objects: []string{"api"}},
+		{name: "prefix", filters: []interface{}{map[string]interface{}{"key": "arn", "value_regex": "^arn:aws:rds:"}}, objects: []string{"db"}},
+		{name: "value under any key", filters: []interface{}{map[string]interface{}{"value": "backend"}}, objects: []string{"api"}},
+		{name: "regex under any key", filters: []interface{}{map[string]interface{}{"value_regex": "orders$"}}, objects: []string{"db"}},
+		{name: "all filters", filters: []interface{}{
+			map[string]interface{}{"key": "team"},
+			map[string]interface{}{"key": "tier", "value": "backend"},
+		}, objects: []string{"api"}},
+		{name: "any filter", matchAny: true, filters: []interface{}{
+			map[string]interface{}{"key": "arn"},
+			map[string]interface{}{"key": "team", "value": "platform"},
+		}, objects: []string{"db", "queue"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			filters, err := parseMetadataFilters(tc.filters)
+			assert.NoError(t, err)
+			objects := make([]string, 0)
+			for _, o := range searchMetadataObjects(entries, filters, tc.matchAny) {
+				objects = append(objects, o.Object)
+			}
+			assert.Equal(t, tc.objects, objects)
+		})
+	}
+}
+
+func TestParseMetadataFilters_invalid(t *testing.T) {
+	for _, tc := range []struct {
+		filter interface{}
+		err    string
+	}{
+		{"team", "filter 0 is not an object"},
+		{map[string]interface{}{}, "filter 0 must set at least one of key, value, or value_regex"},
+		{map[string]interface{}{"exists": true}, "filter 0 must set at least one of key, value, or value_regex"},
+		{map[string]interface{}{"value": "x", "exists": false}, "filter 0 must set a key to check whether it exists"},
+		{map[string]interface{}{"key": "team", "value_regex": "("}, "invalid value_regex in filter 0: error parsing regexp: missing closing ): `(`"},
+	} {
+		_, err := parseMetadataFilters([]interface{}{tc.filter})
+		assert.EqualError(t, err, tc.err)
+	}
+}
@@ -2,7 +2,6 @@ package tools
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
@@ -90,91 +89,115 @@ An optional app_id regex argument can filter Application Ids, while the env_type
 			}
 			envTypeFilter, _ := m["env_type"].(string)
 
-			if r, err := humanitec.CheckResponse(func() (*client.ListApplicationsResponse, error) {
-				return hc.ListApplicationsWithResponse(ctx, orgId)
-			}).AndStatusCodeEq(http.StatusOK).RespAndError(); err != nil {
+			out, appErrors, err := listAppsAndEnvs(ctx, hc, orgId, appIdPattern, envTypeFilter)
+			if err != nil {
 				return nil, err
-			} else {
+			}
 
-				type envstate struct {
-					Name               string    `json:"name"`
-					Type               string    `json:"type"`
-					CreatedTime        time.Time `json:"createdTime"`
-					LastDeploymentId   string    `json:"lastDeploymentId,omitempty"`
-					LastDeploymentSet  string    `json:"lastDeploymentSetId,omitempty"`
-					LastDeploymentTime time.Time `json:"lastDeploymentTime,omitempty"`
-				}
+			rawApps := internal.PrettyJson(out)
+			return append([]mcp.CallToolResponseContent{
+				mcp.NewTextToolResponseContent("The user is has access to the following Humanitec Applications with Organization '%s' in JSON format: %s", orgId, string(rawApps)),
+			}, appErrorsResponse(appErrors)...), nil
+		},
+	}
+}
 
-				type appstate struct {
-					Name         string              `json:"name"`
-					Environments map[string]envstate `json:"environments"`
-					CreatedTime  string              `json:"createdTime"`
-				}
+type envstate struct {
+	Name                 string    `json:"name"`
+	Type                 string    `json:"type"`
+	CreatedTime          time.Time `json:"createdTime"`
+	LastDeploymentId     string    `json:"lastDeploymentId,omitempty"`
+	LastDeploymentSet    string    `json:"lastDeploymentSetId,omitempty"`
+	LastDeploymentTime   time.Time `json:"lastDeploymentTime,omitempty"`
+	LastDeploymentStatus string    `json:"lastDeploymentStatus,omitempty"`
+}
+
+type appstate struct {
+	Name         string              `json:"name"`
+	Environments map[string]envstate `json:"environments"`
+	CreatedTime  string              `json:"createdTime"`
+}
+
+// listAppsAndEnvs fetches the applications in the org and, concurrently, the environments of each application. The
+// optional appIdPattern and envTypeFilter restrict the applications and environments returned. Applications whose
+// environments cannot be fetched, for example because the token cannot read them, are left out of the results and their
+// errors are returned by app id instead, so that one application does not fail the whole org.
+func listAppsAndEnvs(ctx context.Context, hc *humanitec.WrappedHumanitecClientImpl, orgId string, appIdPattern *regexp.Regexp, envTypeFilter string) (map[string]appstate, map[string]string, error) {
+	r, err := humanitec.CheckResponse(func() (*client.ListApplicationsResponse, error) {
+		return hc.ListApplicationsWithResponse(ctx, orgId)
+	}).AndStatusCodeEq(http.StatusOK).RespAndError()
+	if err != nil {
+		return nil, nil, err
+	}
 
-				apps := new(sync.Map)
-				{
-					wg := new(sync.WaitGroup)
-					sem := make(chan struct{}, 10)
-					for _, app := range *r.JSON200 {
-						if appIdPattern != nil && !appIdPattern.MatchString(app.Id) {
+	apps := new(sync.Map)
+	{
+		wg := new(sync.WaitGroup)
+		sem := make(chan struct{}, 10)
+		for _, app := range *r.JSON200 {
+			if appIdPattern != nil && !appIdPattern.MatchString(app.Id) {
+				continue
+			}
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				if r, err := humanitec.CheckResponse(func() (*client.ListEnvironmentsResponse, error) {
+					return hc.ListEnvironmentsWithResponse(ctx, orgId, app.Id)
+				}).AndStatusCodeEq(http.StatusOK).RespAndError(); err != nil {
+					apps.Store(app.Id, err)
+				} else {
+					envs := make(map[string]envstate)
+					for _, e := range *r.JSON200 {
+						if envTypeFilter != "" && e.Type != envTypeFilter {
 							continue
 						}
-						wg.Add(1)
-						sem <- struct{}{}
-						go func() {
-							defer wg.Done()
-							defer func() { <-sem }()
-							if r, err := humanitec.CheckResponse(func() (*client.ListEnvironmentsResponse, error) {
-								return hc.ListEnvironmentsWithResponse(ctx, orgId, app.Id)
-							}).AndStatusCodeEq(http.StatusOK).RespAndError(); err != nil {
-								apps.Store(app.Id, err.Error())
-							} else {
-								envs := make(map[string]envstate)
-								for _, e := range *r.JSON200 {
-									if envTypeFilter != "" && e.Type != envTypeFilter {
-										continue
-									}
-									es := envstate{
-										Name:        e.Name,
-										Type:        e.Type,
-										CreatedTime: e.CreatedAt,
-									}
-									if e.LastDeploy != nil {
-										es.LastDeploymentId = e.LastDeploy.Id
-										es.LastDeploymentSet = e.LastDeploy.SetId
-										es.LastDeploymentTime = e.LastDeploy.CreatedAt
-									}
-									envs[e.Id] = es
-								}
-								apps.Store(app.Id, appstate{
-									Name:         app.Name,
-									CreatedTime:  app.CreatedAt,
-									Environments: envs,
-								})
-							}
-						}()
+						es := envstate{
+							Name:        e.Name,
+							Type:        e.Type,
+							CreatedTime: e.CreatedAt,
+						}
+						if e.LastDeploy != nil {
+							es.LastDeploymentId = e.LastDeploy.Id
+							es.LastDeploymentSet = e.LastDeploy.SetId
+							es.LastDeploymentTime = e.LastDeploy.CreatedAt
+							es.LastDeploymentStatus = e.LastDeploy.Status
+						}
+						envs[e.Id] = es
 					}
-					wg.Wait()
+					apps.Store(app.Id, appstate{
+						Name:         app.Name,
+						CreatedTime:  app.CreatedAt,
+						Environments: envs,
+					})
 				}
+			}()
+		}
+		wg.Wait()
+	}
 
-				out := make(map[string]appstate)
-				apps.Range(func(key, value any) bool {
-					if e, ok := value.(error); ok {
-						err = errors.Join(err, fmt.Errorf("failed to fetch app '%s': %w", key, e))
-					} else if a, ok := value.(appstate); ok {
-						out[key.(string)] = a
-					}
-					return true
-				})
-
-				if err != nil {
-					return nil, err
-				}
+	out := make(map[string]appstate)
+	appErrors := make(map[string]string)
+	apps.Range(func(key, value any) bool {
+		if e, ok := value.(error); ok {
+			appErrors[key.(string)] = e.Error()
+		} else if a, ok := value.(appstate); ok {
+			out[key.(string)] = a
+		}
+		return true
+	})
+	return out, appErrors, nil
+}
 
-				rawApps := internal.PrettyJson(out)
-				return []mcp.CallToolResponseContent{mcp.NewTextToolResponseContent("The user is has access to the following Humanitec Applications with Organization '%s' in JSON format: %s", orgId, string(rawApps))}, nil
-			}
-		},
+// appErrorsResponse reports the applications that are missing from the results of a tool because they could not be
+// fetched.
+func appErrorsResponse(appErrors map[string]string) []mcp.CallToolResponseContent {
+	if len(appErrors) == 0 {
+		return nil
+	}
+	return []mcp.CallToolResponseContent{
+		mcp.NewTextToolResponseContent("The following Applications could not be fetched and are missing from the results, in JSON format: %s", internal.PrettyJson(appErrors)),
 	}
 }
 
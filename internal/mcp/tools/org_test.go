@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/humanitec/humanitec-go-autogen/client"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/humanitec/canyon-cli/internal/clients/humanitec"
+)
+
+// fakeAppsClient serves the applications and environments of an org, failing the environments of some applications.
+type fakeAppsClient struct {
+	client.ClientWithResponsesInterface
+	apps      []client.ApplicationResponse
+	envs      map[string][]client.EnvironmentResponse
+	forbidden map[string]bool
+}
+
+func (f *fakeAppsClient) ListApplicationsWithResponse(ctx context.Context, orgId string, reqEditors ...client.RequestEditorFn) (*client.ListApplicationsResponse, error) {
+	return &client.ListApplicationsResponse{HTTPResponse: &http.Response{StatusCode: http.StatusOK}, JSON200: &f.apps}, nil
+}
+
+func (f *fakeAppsClient) ListEnvironmentsWithResponse(ctx context.Context, orgId string, appId string, reqEditors ...client.RequestEditorFn) (*client.ListEnvironmentsResponse, error) {
+	if f.forbidden[appId] {
+		return &client.ListEnvironmentsResponse{HTTPResponse: &http.Response{StatusCode: http.StatusForbidden}, Body: []byte(`{}`)}, nil
+	}
+	envs := f.envs[appId]
+	return &client.ListEnvironmentsResponse{HTTPResponse: &http.Response{StatusCode: http.StatusOK}, JSON200: &envs}, nil
+}
+
+func TestListAppsAndEnvs_partialResults(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	hc := &humanitec.WrappedHumanitecClientImpl{ClientWithResponsesInterface: &fakeAppsClient{
+		apps: []client.ApplicationResponse{{Id: "shop", Name: "Shop"}, {Id: "secret", Name: "Secret"}},
+		envs: map[string][]client.EnvironmentResponse{
+			"shop": {{Id: "development", Name: "Development", Type: "development", CreatedAt: created}, {Id: "production", Type: "production", CreatedAt: created}},
+		},
+		forbidden: map[string]bool{"secret": true},
+	}}
+
+	apps, appErrors, err := listAppsAndEnvs(context.Background(), hc, "org", nil, "development")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]appstate{
+		"shop": {Name: "Shop", Environments: map[string]envstate{"development": {Name: "Development", Type: "development", CreatedTime: created}}},
+	}, apps)
+	if assert.Len(t, appErrors, 1) {
+		assert.NotEmpty(t, appErrors["secret"])
+	}
+
+	out := appErrorsResponse(appErrors)
+	if assert.Len(t, out, 1) {
+		assert.Contains(t, out[0].TextContent.Text, `"secret": `)
+	}
+	assert.Empty(t, appErrorsResponse(map[string]string{}))
+}
@@ -9,31 +9,20 @@ import (
 	"fmt"
 	"html/template"
 	"log/slog"
-	"math/rand"
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
-	"net/url" // Added for URL parsing/joining
-	"strconv" // Added for string conversion
+	"sync"
 
 	"github.com/Masterminds/sprig/v3"
-	"github.com/minio/minio-go/v7" // Added for Minio client
-	"github.com/minio/minio-go/v7/pkg/credentials" // Added for Minio credentials
-	// "github.com/pkg/browser" // No longer needed
 
+	"github.com/humanitec/canyon-cli/internal/assets"
+	"github.com/humanitec/canyon-cli/internal/config"
+	"github.com/humanitec/canyon-cli/internal/diagram"
 	"github.com/humanitec/canyon-cli/internal/mcp"
+	"github.com/humanitec/canyon-cli/internal/render"
 )
 
-// Simple word list for random filenames
-var randomWords = []string{
-	"apple", "banana", "cherry", "date", "elderberry", "fig", "grape", "honeydew",
-	"kiwi", "lemon", "mango", "nectarine", "orange", "papaya", "quince", "raspberry",
-	"strawberry", "tangerine", "ugli", "vanilla", "watermelon", "xigua", "yam", "zucchini",
-	"red", "green", "blue", "yellow", "purple", "orange", "pink", "brown", "black", "white",
-	"happy", "sad", "big", "small", "fast", "slow", "bright", "dark", "shiny", "dull",
-}
-
 //go:embed render_csv.html.tmpl
 var renderCsvTemplate string
 
@@ -43,12 +32,12 @@ var renderTreeTemplate string
 //go:embed render_graph.html.tmpl
 var renderGraphTemplate string
 
+//go:embed render_timeline.html.tmpl
+var renderTimelineTemplate string
+
 var funcMap template.FuncMap
 
 func init() {
-	// Seed random number generator
-	rand.New(rand.NewSource(time.Now().UnixNano()))
-
 	f := func(path string, defaultContent string) string {
 		raw, err := os.ReadFile(path)
 		if err == nil {
@@ -66,6 +55,7 @@ func init() {
 		renderCsvTemplate = f(filepath.Join(h, "canyon-render-csv-template.html.tmpl"), renderCsvTemplate)
 		renderTreeTemplate = f(filepath.Join(h, "canyon-render-tree-template.html.tmpl"), renderTreeTemplate)
 		renderGraphTemplate = f(filepath.Join(h, "canyon-render-graph-template.html.tmpl"), renderGraphTemplate)
+		renderTimelineTemplate = f(filepath.Join(h, "canyon-render-timeline-template.html.tmpl"), renderTimelineTemplate)
 	}
 
 	funcMap = sprig.HtmlFuncMap()
@@ -73,139 +63,203 @@ func init() {
 		raw, _ := json.Marshal(content)
 		return template.JS(raw)
 	}
+	for k, v := range assets.FuncMap() {
+		funcMap[k] = v
+	}
 }
 
-// generateRandomFilename creates a filename like "word1-word2-word3-12345.html"
-func generateRandomFilename() string {
-	n := len(randomWords)
-	wordsPart := fmt.Sprintf("%d", time.Now().UnixNano()) // Fallback if word list is empty
-	if n > 0 {
-		word1 := randomWords[rand.Intn(n)]
-		word2 := randomWords[rand.Intn(n)]
-		word3 := randomWords[rand.Intn(n)]
-		wordsPart = fmt.Sprintf("%s-%s-%s", word1, word2, word3)
+// renderStore is the store the rendered artifacts are uploaded to. It is loaded from the config on first use.
+var renderStore = sync.OnceValues(func() (render.RenderStore, error) {
+	c, err := config.Load()
+	if err != nil {
+		return nil, err
 	}
+	return render.NewFromConfig(c.Render)
+})
 
-	// Generate 5 random digits
-	digits := fmt.Sprintf("%05d", rand.Intn(100000)) // Generates a number between 0 and 99999, pads with leading zeros if needed
+// SetRenderStore overrides the render store from the config, for example with the embedded render server.
+func SetRenderStore(store render.RenderStore) {
+	renderStore = func() (render.RenderStore, error) {
+		return store, nil
+	}
+}
 
-	return fmt.Sprintf("%s-%s.html", wordsPart, digits)
+// renderResourcePrefix is the uri prefix of the renders registered as resources.
+const renderResourcePrefix = "canyon://renders/"
+
+// renderResources holds the renders of this session so that clients can read them back with resources/read.
+var renderResources = mcp.NewResourceRegistry()
+
+// renderResult is an uploaded render.
+type renderResult struct {
+	// Url is the link to view the render from the render store.
+	Url string
+	// Uri is the uri of the render registered as a resource.
+	Uri string
+	// MimeType is the content type of the render, text/html or image/svg+xml.
+	MimeType string
+	// Content is the rendered content.
+	Content []byte
 }
 
-// renderAndUploadToMinio takes the rendered HTML buffer, uploads it to Minio,
-// and returns a public URL. Configuration is read from environment variables.
-func renderAndUploadToMinio(ctx context.Context, buffer *bytes.Buffer) (string, error) {
-	// 1. Read configuration from environment variables
-	endpoint := os.Getenv("MINIO_ENDPOINT")
-	accessKeyID := os.Getenv("MINIO_ACCESS_KEY_ID")
-	secretAccessKey := os.Getenv("MINIO_SECRET_ACCESS_KEY")
-	bucketName := os.Getenv("MINIO_BUCKET")
-	useSSLStr := os.Getenv("MINIO_USE_SSL") // Expect "true" or "false"
-
-	if endpoint == "" || accessKeyID == "" || secretAccessKey == "" || bucketName == "" {
-		return "", fmt.Errorf("missing required Minio environment variables (MINIO_ENDPOINT, MINIO_ACCESS_KEY_ID, MINIO_SECRET_ACCESS_KEY, MINIO_BUCKET)")
+// uploadRender stores the rendered content in the configured render store and registers it as a resource. Identical
+// renders are deduplicated, unless a slug is given, in which case the render under that slug is updated in place.
+func uploadRender(ctx context.Context, mimeType string, ext string, content []byte, slug string) (renderResult, error) {
+	store, err := renderStore()
+	if err != nil {
+		return renderResult{}, fmt.Errorf("failed to set up the render store: %w", err)
+	}
+	name, err := render.StableName(ext, content, slug)
+	if err != nil {
+		return renderResult{}, err
 	}
+	u, err := render.PutContent(ctx, store, mimeType, ext, content, slug)
+	if err != nil {
+		return renderResult{}, err
+	}
+	r := renderResult{Url: u, Uri: renderResourcePrefix + name, MimeType: mimeType, Content: content}
+	renderResources.Add(mcp.Resource{
+		Uri:         r.Uri,
+		Name:        name,
+		Description: "A render uploaded to " + u,
+		Size:        int64(len(content)),
+		MimeType:    mimeType,
+	}, mcp.NewTextResourceContent(r.Uri, mimeType, string(content)))
+	return r, nil
+}
 
-	useSSL := true // Default to true if not specified or invalid
-	if useSSLStr != "" {
-		parsedSSL, err := strconv.ParseBool(useSSLStr)
-		if err == nil {
-			useSSL = parsedSSL
-		} else {
-			slog.Warn("Invalid MINIO_USE_SSL value, defaulting to true", slog.String("value", useSSLStr), slog.Any("error", err))
+// toolResponse describes the uploaded render, optionally with the content embedded for clients that can show it inline.
+// HTML is embedded as a resource and SVG as image content.
+func (r renderResult) toolResponse(label string, embed bool) []mcp.CallToolResponseContent {
+	out := []mcp.CallToolResponseContent{mcp.NewTextToolResponseContent("%s rendered and uploaded: %s (resource %s)", label, r.Url, r.Uri)}
+	if embed && strings.HasPrefix(r.MimeType, "image/") {
+		out = append(out, mcp.NewImageToolResponseContent(r.MimeType, r.Content))
+	} else if embed {
+		out = append(out, mcp.NewEmbeddedTextResourceToolResponseContent(r.Uri, r.MimeType, string(r.Content)))
+	}
+	return out
+}
+
+// diagramFormatsProperty is the input schema of the optional text formats to also return a diagram in.
+var diagramFormatsProperty = map[string]interface{}{
+	"type":        "array",
+	"description": "Optionally also return the diagram as text in these formats, to paste into Markdown or Confluence without hosting HTML: 'mermaid' or Graphviz 'dot'",
+	"items":       map[string]interface{}{"type": "string", "enum": []interface{}{diagram.FormatMermaid, diagram.FormatDot}},
+}
+
+var diagramFormatTitles = map[string]string{diagram.FormatMermaid: "Mermaid", diagram.FormatDot: "Graphviz DOT"}
+
+// diagramTextResponses exports the diagram in each of the requested diagram_formats as a fenced code block.
+func diagramTextResponses(arguments map[string]interface{}, export func(format string) (string, error)) ([]mcp.CallToolResponseContent, error) {
+	formats, _ := arguments["diagram_formats"].([]interface{})
+	out := make([]mcp.CallToolResponseContent, 0, len(formats))
+	for _, f := range formats {
+		format, _ := f.(string)
+		text, err := export(format)
+		if err != nil {
+			return nil, err
 		}
+		out = append(out, mcp.NewTextToolResponseContent("%s diagram:\n```%s\n%s```", diagramFormatTitles[format], format, text))
 	}
+	return out, nil
+}
+
+// previewProperty is the input schema of the option to upload a static SVG preview alongside the HTML render.
+var previewProperty = map[string]interface{}{
+	"type":        "boolean",
+	"description": "Whether to also upload a static SVG preview of the diagram, laid out without a browser, for pasting into docs and tickets",
+}
 
-	// Remove potential scheme (like https://) from endpoint for Minio client
-	endpointURL, err := url.Parse(endpoint)
+// uploadRenderWithPreview uploads the HTML render and, when the preview argument is set, a static SVG preview of the
+// diagram alongside it. The preview is optional, so a diagram that cannot be laid out is logged and only the HTML is
+// uploaded.
+func uploadRenderWithPreview(ctx context.Context, label string, buffer *bytes.Buffer, layout func() (diagram.Layout, error), arguments map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+	slug, _ := arguments["slug"].(string)
+	embed, _ := arguments["embed"].(bool)
+	withPreview, _ := arguments["preview"].(bool)
+	r, err := uploadRender(ctx, "text/html", ".html", buffer.Bytes(), slug)
 	if err != nil {
-		return "", fmt.Errorf("invalid MINIO_ENDPOINT format: %w", err)
+		return nil, err
 	}
-	minioEndpoint := endpointURL.Host // Use host:port
-
-	// 2. Initialize Minio client
-	minioClient, err := minio.New(minioEndpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(accessKeyID, secretAccessKey, ""),
-		Secure: useSSL,
-	})
+	out := r.toolResponse(label, embed)
+	if !withPreview {
+		return out, nil
+	}
+	preview, err := layout()
+	if err != nil {
+		slog.Warn("failed to lay out the static preview", slog.String("render", label), slog.Any("err", err))
+		return append(out, mcp.NewTextToolResponseContent("%s preview could not be rendered: %v", label, err.Error())), nil
+	}
+	p, err := uploadRender(ctx, "image/svg+xml", ".svg", preview.SVG(), slug)
 	if err != nil {
-		return "", fmt.Errorf("failed to create Minio client: %w", err)
+		return nil, err
 	}
+	return append(out, p.toolResponse(label+" preview", embed)...), nil
+}
 
-	// 3. Generate filename (object name)
-	filename := generateRandomFilename()
-	objectName := filename // Use filename directly as object name in Minio
+var csvTableTemplate = sync.OnceValues(func() (*template.Template, error) {
+	return template.New("").Funcs(funcMap).Parse(renderCsvTemplate)
+})
 
-	// 4. Upload the content
-	// Use PutObject with buffer.Bytes() and buffer.Len()
-	uploadInfo, err := minioClient.PutObject(ctx, bucketName, objectName, buffer, int64(buffer.Len()), minio.PutObjectOptions{
-		ContentType: "text/html",
-		// Consider adding Cache-Control: CacheControl: "public, max-age=...",
-	})
+// renderCsvAsTable renders csv as an HTML table and uploads it to the render store. This allows other tools to offer
+// rendering their tabular results directly.
+func renderCsvAsTable(ctx context.Context, raw string, firstRowIsHeader bool, slug string) (renderResult, error) {
+	tmpl, err := csvTableTemplate()
 	if err != nil {
-		return "", fmt.Errorf("failed to upload object to Minio: %w", err)
+		return renderResult{}, err
 	}
-	slog.Info("Successfully uploaded to Minio", slog.String("bucket", bucketName), slog.String("object", objectName), slog.Int64("size", uploadInfo.Size))
 
-	// 5. Construct the public URL
-	// Ensure endpoint has scheme for proper URL construction
-	publicURL := fmt.Sprintf("%s/%s/%s", endpoint, bucketName, objectName)
+	// Validate CSV input
+	r := csv.NewReader(strings.NewReader(raw))
+	if _, err := r.ReadAll(); err != nil {
+		return renderResult{}, fmt.Errorf("invalid csv content: %w", err)
+	}
 
-	// Validate and potentially clean up the URL (e.g., remove double slashes if endpoint already has trailing slash)
-	parsedPublicURL, err := url.Parse(publicURL)
-	if err != nil {
-		slog.Error("Failed to parse constructed public URL, returning raw string", slog.String("url", publicURL), slog.Any("error", err))
-		return publicURL, nil // Return best effort URL even if parsing fails
+	// Render template to buffer
+	buffer := new(bytes.Buffer)
+	if err := tmpl.Execute(buffer, map[string]interface{}{"raw": raw, "first_row_is_header": firstRowIsHeader}); err != nil {
+		slog.Error("failed to execute csv template", slog.Any("err", err))
+		return renderResult{}, fmt.Errorf("could not render csv html content: %w", err)
 	}
-	// Basic path cleaning
-	parsedPublicURL.Path = filepath.Join(parsedPublicURL.Path) // Should handle extra slashes
 
-	return parsedPublicURL.String(), nil
+	// Upload and get URL
+	return uploadRender(ctx, "text/html", ".html", buffer.Bytes(), slug)
 }
 
-// NewRenderCSVAsTable renders csv as a table and uploads to Minio.
+// NewRenderCSVAsTable renders csv as a table and uploads to the render store.
 func NewRenderCSVAsTable() mcp.Tool {
-	tmpl, err := template.New("").Funcs(funcMap).Parse(renderCsvTemplate)
-	if err != nil {
+	if _, err := csvTableTemplate(); err != nil {
 		panic(err)
 	}
 	return mcp.Tool{
 		Name:        "render_csv_as_table_to_minio",
-		Description: `This tool renders CSV data as an HTML table and uploads it to Minio, returning a public link. Requires MINIO_* env vars to be set.`,
+		Description: `This tool renders CSV data as an HTML table and uploads it to the configured render store, returning a link to view it.`,
 		InputSchema: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
 				"raw":                 map[string]interface{}{"type": "string", "description": "The raw multiline csv content"},
 				"first_row_is_header": map[string]interface{}{"type": "boolean", "description": "Whether the first row of csv is the header"},
+				"slug":                map[string]interface{}{"type": "string", "description": "An optional stable name for the render. Rendering again with the same slug updates the render in place instead of creating a new link"},
+				"embed":               map[string]interface{}{"type": "boolean", "description": "Whether to also return the rendered HTML as an embedded resource, for clients that can show it inline"},
 			},
 			"required": []interface{}{"raw"},
 		},
 		Callable: func(ctx context.Context, arguments map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
-			// Validate CSV input
-			r := csv.NewReader(strings.NewReader(arguments["raw"].(string)))
-			if _, err := r.ReadAll(); err != nil {
-				return nil, fmt.Errorf("invalid csv content: %w", err)
-			}
-
-			// Render template to buffer
-			buffer := new(bytes.Buffer)
-			if err := tmpl.Execute(buffer, arguments); err != nil {
-				slog.Error("failed to execute csv template", slog.Any("err", err))
-				return nil, fmt.Errorf("could not render csv html content: %w", err)
-			}
-
-			// Upload and get URL
-			publicURL, err := renderAndUploadToMinio(ctx, buffer)
+			raw, _ := arguments["raw"].(string)
+			firstRowIsHeader, _ := arguments["first_row_is_header"].(bool)
+			slug, _ := arguments["slug"].(string)
+			embed, _ := arguments["embed"].(bool)
+			r, err := renderCsvAsTable(ctx, raw, firstRowIsHeader, slug)
 			if err != nil {
 				return nil, err // Error already contains details
 			}
 
-			return []mcp.CallToolResponseContent{mcp.NewTextToolResponseContent("CSV rendered and uploaded: " + publicURL)}, nil
+			return r.toolResponse("CSV", embed), nil
 		},
 	}
 }
 
-// NewRenderTreeAsTree renders a hierarchy and uploads to Minio.
+// NewRenderTreeAsTree renders a hierarchy and uploads to the render store.
 func NewRenderTreeAsTree() mcp.Tool {
 	tmpl, err := template.New("").Funcs(funcMap).Parse(renderTreeTemplate)
 	if err != nil {
@@ -213,21 +267,25 @@ func NewRenderTreeAsTree() mcp.Tool {
 	}
 	return mcp.Tool{
 		Name:        "render_data_as_tree_to_minio",
-		Description: `This tool renders hierarchical data (like a tree structure) as HTML and uploads it to Minio, returning a public link. Requires MINIO_* env vars to be set.`,
+		Description: `This tool renders hierarchical data (like a tree structure) as HTML and uploads it to the configured render store, optionally together with a static SVG preview, returning links to view them.`,
 		InputSchema: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
-				"root": map[string]interface{}{"$ref": "#/$defs/node", "description": "The root of the tree structure"},
+				"root":            map[string]interface{}{"$ref": "#/$defs/node", "description": "The root of the tree structure"},
+				"slug":            map[string]interface{}{"type": "string", "description": "An optional stable name for the render. Rendering again with the same slug updates the render in place instead of creating a new link"},
+				"embed":           map[string]interface{}{"type": "boolean", "description": "Whether to also return the rendered HTML as an embedded resource and the static SVG preview as image content, for clients that can show them inline"},
+				"preview":         previewProperty,
+				"diagram_formats": diagramFormatsProperty,
 			},
 			"required": []interface{}{"root"},
 			"$defs": map[string]interface{}{
 				"node": map[string]interface{}{
-					"type": "object",
+					"type":        "object",
 					"description": "A node in the tree structure",
 					"properties": map[string]interface{}{
 						"name":     map[string]interface{}{"type": "string", "description": "The name of the node"},
 						"class":    map[string]interface{}{"type": "string", "description": "The class of the node. Well known classes are: 'org', 'app', 'env_type', 'env', 'workload', 'resource', and 'other' but arbitrary strings can be used too"},
-						"data":     map[string]interface{}{"type": "object", "description": "Arbitrary additional metadata to include on the node visualisation"},
+						"data":     map[string]interface{}{"type": "object", "description": "Arbitrary additional metadata to include on the node visualisation. External links are added from the configured link providers"},
 						"children": map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/$defs/node"}},
 					},
 					"required": []interface{}{"name", "class"},
@@ -235,25 +293,41 @@ func NewRenderTreeAsTree() mcp.Tool {
 			},
 		},
 		Callable: func(ctx context.Context, arguments map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+			providers, err := linkProviders()
+			if err != nil {
+				return nil, fmt.Errorf("failed to load the link providers: %w", err)
+			}
+			root, _ := arguments["root"].(map[string]interface{})
+			root = addTreeLinks(providers, root)
+
 			// Render template to buffer
 			buffer := new(bytes.Buffer)
-			if err := tmpl.Execute(buffer, arguments); err != nil { // Pass arguments directly
+			if err := tmpl.Execute(buffer, map[string]interface{}{"root": root}); err != nil {
 				slog.Error("failed to execute tree template", slog.Any("err", err))
 				return nil, fmt.Errorf("could not render tree html content: %w", err)
 			}
 
-			// Upload and get URL
-			publicURL, err := renderAndUploadToMinio(ctx, buffer)
+			tree := diagramTree(root)
+			text, err := diagramTextResponses(arguments, func(format string) (string, error) {
+				return diagram.ExportTree(tree, format)
+			})
 			if err != nil {
-				return nil, err // Error already contains details
+				return nil, err
 			}
 
-			return []mcp.CallToolResponseContent{mcp.NewTextToolResponseContent("Tree rendered and uploaded: " + publicURL)}, nil
+			// Upload with an optional static preview and get URLs
+			out, err := uploadRenderWithPreview(ctx, "Tree", buffer, func() (diagram.Layout, error) {
+				return diagram.LayoutTree(tree), nil
+			}, arguments)
+			if err != nil {
+				return nil, err
+			}
+			return append(out, text...), nil
 		},
 	}
 }
 
-// NewRenderNetworkAsGraph renders a network graph and uploads to Minio.
+// NewRenderNetworkAsGraph renders a network graph and uploads to the render store.
 func NewRenderNetworkAsGraph() mcp.Tool {
 	tmpl, err := template.New("").Funcs(funcMap).Parse(renderGraphTemplate)
 	if err != nil {
@@ -261,7 +335,7 @@ func NewRenderNetworkAsGraph() mcp.Tool {
 	}
 	return mcp.Tool{
 		Name:        "render_network_as_graph_to_minio",
-		Description: `This tool renders an interconnected network as a force-directed graph in HTML and uploads it to Minio, returning a public link. Requires MINIO_* env vars to be set.`,
+		Description: `This tool renders an interconnected network as a force-directed graph in HTML and uploads it to the configured render store, optionally together with a static layered SVG preview, returning links to view them.`,
 		InputSchema: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -271,7 +345,7 @@ func NewRenderNetworkAsGraph() mcp.Tool {
 					"properties": map[string]interface{}{
 						"id":    map[string]interface{}{"type": "string"},
 						"class": map[string]interface{}{"type": "string", "description": "The class of the node. Well known classes are: 'org', 'app', 'env_type', 'env', 'workload', 'resource', and 'other' but arbitrary strings can be used too"},
-						"data":  map[string]interface{}{"type": "object", "description": "Arbitrary additional metadata to include on the node visualisation"},
+						"data":  map[string]interface{}{"type": "object", "description": "Arbitrary additional metadata to include on the node visualisation. External links are added from the configured link providers"},
 					},
 					"required": []interface{}{"id", "class"},
 				}},
@@ -285,24 +359,82 @@ func NewRenderNetworkAsGraph() mcp.Tool {
 					},
 					"required": []interface{}{"source", "target"},
 				}},
+				"slug":            map[string]interface{}{"type": "string", "description": "An optional stable name for the render. Rendering again with the same slug updates the render in place instead of creating a new link"},
+				"embed":           map[string]interface{}{"type": "boolean", "description": "Whether to also return the rendered HTML as an embedded resource and the static SVG preview as image content, for clients that can show them inline"},
+				"preview":         previewProperty,
+				"diagram_formats": diagramFormatsProperty,
 			},
 			"required": []interface{}{"nodes", "links"},
 		},
 		Callable: func(ctx context.Context, arguments map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+			providers, err := linkProviders()
+			if err != nil {
+				return nil, fmt.Errorf("failed to load the link providers: %w", err)
+			}
+			nodes, _ := arguments["nodes"].([]interface{})
+			nodes = addGraphLinks(providers, nodes)
+
 			// Render template to buffer
 			buffer := new(bytes.Buffer)
-			if err := tmpl.Execute(buffer, arguments); err != nil {
+			if err := tmpl.Execute(buffer, map[string]interface{}{"nodes": nodes, "links": arguments["links"]}); err != nil {
 				slog.Error("failed to execute graph template", slog.Any("err", err))
 				return nil, fmt.Errorf("could not render graph html content: %w", err)
 			}
 
-			// Upload and get URL
-			publicURL, err := renderAndUploadToMinio(ctx, buffer)
+			graph := diagramGraph(nodes, arguments["links"])
+			text, err := diagramTextResponses(arguments, func(format string) (string, error) {
+				return diagram.ExportGraph(graph, format)
+			})
 			if err != nil {
-				return nil, err // Error already contains details
+				return nil, err
 			}
 
-			return []mcp.CallToolResponseContent{mcp.NewTextToolResponseContent("Graph rendered and uploaded: " + publicURL)}, nil
+			// Upload with an optional static preview and get URLs
+			out, err := uploadRenderWithPreview(ctx, "Graph", buffer, func() (diagram.Layout, error) {
+				return diagram.LayoutGraph(graph)
+			}, arguments)
+			if err != nil {
+				return nil, err
+			}
+			return append(out, text...), nil
 		},
 	}
 }
+
+// diagramTree converts the root argument of the tree render tool for the static layout.
+func diagramTree(node map[string]interface{}) diagram.TreeNode {
+	out := diagram.TreeNode{}
+	out.Name, _ = node["name"].(string)
+	out.Class, _ = node["class"].(string)
+	children, _ := node["children"].([]interface{})
+	for _, child := range children {
+		if c, ok := child.(map[string]interface{}); ok {
+			out.Children = append(out.Children, diagramTree(c))
+		}
+	}
+	return out
+}
+
+// diagramGraph converts the nodes and links arguments of the graph render tool for the static layout.
+func diagramGraph(nodes []interface{}, links interface{}) diagram.Graph {
+	out := diagram.Graph{}
+	for _, node := range nodes {
+		if n, ok := node.(map[string]interface{}); ok {
+			dn := diagram.Node{}
+			dn.Id, _ = n["id"].(string)
+			dn.Class, _ = n["class"].(string)
+			out.Nodes = append(out.Nodes, dn)
+		}
+	}
+	rawLinks, _ := links.([]interface{})
+	for _, link := range rawLinks {
+		if l, ok := link.(map[string]interface{}); ok {
+			dl := diagram.Link{}
+			dl.Source, _ = l["source"].(string)
+			dl.Target, _ = l["target"].(string)
+			dl.Label, _ = l["explanation"].(string)
+			out.Links = append(out.Links, dl)
+		}
+	}
+	return out
+}
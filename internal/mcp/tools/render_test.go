@@ -0,0 +1,138 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/humanitec/canyon-cli/internal/render"
+)
+
+func TestRenderCsvAsTable(t *testing.T) {
+	store := render.NewMemoryStore()
+	previous := renderStore
+	renderStore = func() (render.RenderStore, error) { return store, nil }
+	t.Cleanup(func() { renderStore = previous })
+
+	r, err := renderCsvAsTable(context.Background(), "a,b\n1,2\n", true, "")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(r.Url, "memory://"))
+	o, ok := store.Get(strings.TrimPrefix(r.Url, "memory://"))
+	assert.True(t, ok)
+	assert.Equal(t, "text/html", o.ContentType)
+	assert.Contains(t, string(o.Content), "a,b")
+}
+
+func TestRenderToolEmbedsResource(t *testing.T) {
+	previous := renderStore
+	renderStore = func() (render.RenderStore, error) { return render.NewMemoryStore(), nil }
+	t.Cleanup(func() { renderStore = previous })
+
+	out, err := NewRenderCSVAsTable().Callable(context.Background(), map[string]interface{}{
+		"raw": "a,b\n1,2\n", "slug": "My Table", "embed": true,
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, out, 2) {
+		assert.Equal(t, "CSV rendered and uploaded: memory://my-table.html (resource canyon://renders/my-table.html)", out[0].TextContent.Text)
+		assert.Equal(t, "canyon://renders/my-table.html", out[1].EmbeddedResource.Resource.TextResourceContent.Uri)
+		assert.Contains(t, out[1].EmbeddedResource.Resource.TextResourceContent.Text, "a,b")
+	}
+
+	c, ok := renderResources.Read("canyon://renders/my-table.html")
+	assert.True(t, ok)
+	assert.Equal(t, out[1].EmbeddedResource.Resource.TextResourceContent.Text, c.TextResourceContent.Text)
+}
+
+func TestRenderGraphUploadsSvgPreview(t *testing.T) {
+	store := render.NewMemoryStore()
+	previous := renderStore
+	renderStore = func() (render.RenderStore, error) { return store, nil }
+	t.Cleanup(func() { renderStore = previous })
+
+	out, err := NewRenderNetworkAsGraph().Callable(context.Background(), map[string]interface{}{
+		"nodes": []interface{}{
+			map[string]interface{}{"id": "app", "class": "app"},
+			map[string]interface{}{"id": "db", "class": "resource"},
+		},
+		"links": []interface{}{map[string]interface{}{"source": "app", "target": "db", "explanation": "uses"}},
+		"slug":  "topology", "embed": true, "preview": true,
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, out, 4) {
+		assert.Equal(t, "Graph preview rendered and uploaded: memory://topology.svg (resource canyon://renders/topology.svg)", out[2].TextContent.Text)
+		assert.Equal(t, "image/svg+xml", out[3].ImageContent.MimeType)
+	}
+	o, ok := store.Get("topology.svg")
+	assert.True(t, ok)
+	assert.Contains(t, string(o.Content), ">uses</text>")
+
+	// without the preview argument only the HTML is uploaded
+	out, err = NewRenderNetworkAsGraph().Callable(context.Background(), map[string]interface{}{
+		"nodes": []interface{}{map[string]interface{}{"id": "app", "class": "app"}},
+		"links": []interface{}{},
+		"slug":  "html-only",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, out, 1)
+	_, ok = store.Get("html-only.svg")
+	assert.False(t, ok)
+}
+
+func TestRenderGraphWithoutPreviewLayout(t *testing.T) {
+	store := render.NewMemoryStore()
+	previous := renderStore
+	renderStore = func() (render.RenderStore, error) { return store, nil }
+	t.Cleanup(func() { renderStore = previous })
+
+	// graphs that the D3 render tolerates but the static layout rejects still produce the HTML render
+	for name, arguments := range map[string]map[string]interface{}{
+		"dangling-link": {
+			"nodes": []interface{}{map[string]interface{}{"id": "app", "class": "app"}},
+			"links": []interface{}{map[string]interface{}{"source": "app", "target": "missing"}},
+		},
+		"duplicate-node": {
+			"nodes": []interface{}{map[string]interface{}{"id": "app", "class": "app"}, map[string]interface{}{"id": "app", "class": "app"}},
+			"links": []interface{}{},
+		},
+	} {
+		arguments["slug"] = name
+		arguments["preview"] = true
+		out, err := NewRenderNetworkAsGraph().Callable(context.Background(), arguments)
+		assert.NoError(t, err)
+		if assert.Len(t, out, 2) {
+			assert.Equal(t, "Graph rendered and uploaded: memory://"+name+".html (resource canyon://renders/"+name+".html)", out[0].TextContent.Text)
+			assert.True(t, strings.HasPrefix(out[1].TextContent.Text, "Graph preview could not be rendered: "))
+		}
+		_, ok := store.Get(name + ".html")
+		assert.True(t, ok)
+		_, ok = store.Get(name + ".svg")
+		assert.False(t, ok)
+	}
+}
+
+func TestRenderTreeDiagramFormats(t *testing.T) {
+	previous := renderStore
+	renderStore = func() (render.RenderStore, error) { return render.NewMemoryStore(), nil }
+	t.Cleanup(func() { renderStore = previous })
+
+	out, err := NewRenderTreeAsTree().Callable(context.Background(), map[string]interface{}{
+		"root": map[string]interface{}{"name": "org", "class": "org", "children": []interface{}{
+			map[string]interface{}{"name": "app", "class": "app"},
+		}},
+		"diagram_formats": []interface{}{"mermaid", "dot"},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, out, 3) {
+		assert.True(t, strings.HasPrefix(out[1].TextContent.Text, "Mermaid diagram:\n```mermaid\nflowchart TD\n    n0{{\"org\"}}\n"))
+		assert.True(t, strings.HasPrefix(out[2].TextContent.Text, "Graphviz DOT diagram:\n```dot\ndigraph canyon {\n"))
+		assert.True(t, strings.HasSuffix(out[2].TextContent.Text, "}\n```"))
+	}
+
+	_, err = NewRenderTreeAsTree().Callable(context.Background(), map[string]interface{}{
+		"root":            map[string]interface{}{"name": "org", "class": "org"},
+		"diagram_formats": []interface{}{"png"},
+	})
+	assert.EqualError(t, err, "unsupported diagram format 'png', expected one of mermaid, dot")
+}
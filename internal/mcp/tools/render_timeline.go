@@ -0,0 +1,141 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/humanitec/canyon-cli/internal/mcp"
+)
+
+// timelineEvent is an event on a lane of the timeline render. Events without an end are drawn as points in time.
+type timelineEvent struct {
+	Id     string                 `json:"id"`
+	Label  string                 `json:"label"`
+	Lane   string                 `json:"lane"`
+	Start  time.Time              `json:"start"`
+	End    *time.Time             `json:"end,omitempty"`
+	Status string                 `json:"status,omitempty"`
+	Data   map[string]interface{} `json:"data,omitempty"`
+}
+
+// parseTimelineEvents validates the events and lanes arguments of the timeline render tool. The lanes are in the given
+// order followed by any other lanes in order of first appearance, and the events are sorted by their start time.
+func parseTimelineEvents(rawEvents []interface{}, rawLanes []interface{}) ([]string, []timelineEvent, error) {
+	lanes := make([]string, 0, len(rawLanes))
+	for _, l := range rawLanes {
+		if l, ok := l.(string); ok && l != "" && !slices.Contains(lanes, l) {
+			lanes = append(lanes, l)
+		}
+	}
+
+	events := make([]timelineEvent, 0, len(rawEvents))
+	for i, raw := range rawEvents {
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("event %d is not an object", i)
+		}
+		e := timelineEvent{}
+		e.Id, _ = m["id"].(string)
+		e.Label, _ = m["label"].(string)
+		e.Lane, _ = m["lane"].(string)
+		e.Status, _ = m["status"].(string)
+		e.Data, _ = m["data"].(map[string]interface{})
+		if e.Lane == "" {
+			return nil, nil, fmt.Errorf("event %d has no lane", i)
+		}
+		start, _ := m["start"].(string)
+		var err error
+		if e.Start, err = time.Parse(time.RFC3339, start); err != nil {
+			return nil, nil, fmt.Errorf("event %d has an invalid start time: %w", i, err)
+		}
+		if end, _ := m["end"].(string); end != "" {
+			t, err := time.Parse(time.RFC3339, end)
+			if err != nil {
+				return nil, nil, fmt.Errorf("event %d has an invalid end time: %w", i, err)
+			} else if t.Before(e.Start) {
+				return nil, nil, fmt.Errorf("event %d ends before it starts", i)
+			}
+			e.End = &t
+		}
+		if e.Id == "" {
+			e.Id = fmt.Sprintf("event-%d", i)
+		}
+		if e.Label == "" {
+			e.Label = e.Id
+		}
+		if !slices.Contains(lanes, e.Lane) {
+			lanes = append(lanes, e.Lane)
+		}
+		events = append(events, e)
+	}
+	slices.SortStableFunc(events, func(a, b timelineEvent) int {
+		return a.Start.Compare(b.Start)
+	})
+	return lanes, events, nil
+}
+
+// NewRenderTimeline renders events as a swimlane timeline and uploads to the render store.
+func NewRenderTimeline() mcp.Tool {
+	tmpl, err := template.New("").Funcs(funcMap).Parse(renderTimelineTemplate)
+	if err != nil {
+		panic(err)
+	}
+	return mcp.Tool{
+		Name:        "render_timeline_to_minio",
+		Description: `This tool renders events over time, like the deployment history of an application, as an interactive swimlane timeline in HTML with one lane per environment and uploads it to the configured render store, returning a link to view it.`,
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"title": map[string]interface{}{"type": "string", "description": "An optional title shown above the timeline"},
+				"events": map[string]interface{}{"type": "array", "description": "The list of events on the timeline", "items": map[string]interface{}{
+					"type":        "object",
+					"description": "An event on the timeline",
+					"properties": map[string]interface{}{
+						"id":     map[string]interface{}{"type": "string", "description": "An optional id of the event, such as the deployment id"},
+						"label":  map[string]interface{}{"type": "string", "description": "The short label of the event, defaults to the id"},
+						"lane":   map[string]interface{}{"type": "string", "description": "The lane the event is shown in, such as the environment id"},
+						"start":  map[string]interface{}{"type": "string", "format": "date-time", "description": "The RFC3339 time the event started"},
+						"end":    map[string]interface{}{"type": "string", "format": "date-time", "description": "The optional RFC3339 time the event ended. Events without an end are shown as points in time"},
+						"status": map[string]interface{}{"type": "string", "description": "The status of the event. Well known statuses are: 'succeeded', 'failed', 'in progress', 'pending', and 'cancelled' but arbitrary strings can be used too"},
+						"data":   map[string]interface{}{"type": "object", "description": "Arbitrary additional metadata to include in the event details"},
+					},
+					"required": []interface{}{"lane", "start"},
+				}},
+				"lanes": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "The optional order of the lanes from top to bottom, such as development, staging, production. Lanes that are not listed follow in order of first appearance"},
+				"slug":  map[string]interface{}{"type": "string", "description": "An optional stable name for the render. Rendering again with the same slug updates the render in place instead of creating a new link"},
+				"embed": map[string]interface{}{"type": "boolean", "description": "Whether to also return the rendered HTML as an embedded resource, for clients that can show it inline"},
+			},
+			"required": []interface{}{"events"},
+		},
+		Callable: func(ctx context.Context, arguments map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+			rawEvents, _ := arguments["events"].([]interface{})
+			rawLanes, _ := arguments["lanes"].([]interface{})
+			lanes, events, err := parseTimelineEvents(rawEvents, rawLanes)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timeline: %w", err)
+			}
+			title, _ := arguments["title"].(string)
+			slug, _ := arguments["slug"].(string)
+			embed, _ := arguments["embed"].(bool)
+
+			// Render template to buffer
+			buffer := new(bytes.Buffer)
+			if err := tmpl.Execute(buffer, map[string]interface{}{"title": title, "lanes": lanes, "events": events}); err != nil {
+				slog.Error("failed to execute timeline template", slog.Any("err", err))
+				return nil, fmt.Errorf("could not render timeline html content: %w", err)
+			}
+
+			// Upload and get URL
+			r, err := uploadRender(ctx, "text/html", ".html", buffer.Bytes(), slug)
+			if err != nil {
+				return nil, err
+			}
+			return r.toolResponse("Timeline", embed), nil
+		},
+	}
+}
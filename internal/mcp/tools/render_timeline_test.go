@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/humanitec/canyon-cli/internal/render"
+)
+
+func TestParseTimelineEvents(t *testing.T) {
+	lanes, events, err := parseTimelineEvents([]interface{}{
+		map[string]interface{}{"id": "d2", "lane": "production", "start": "2024-01-02T10:00:00Z", "end": "2024-01-02T10:05:00Z", "status": "failed"},
+		map[string]interface{}{"lane": "development", "start": "2024-01-01T10:00:00Z", "data": map[string]interface{}{"delta": "abc"}},
+		map[string]interface{}{"id": "d3", "label": "Hotfix", "lane": "staging", "start": "2024-01-01T12:00:00+01:00"},
+	}, []interface{}{"development", "staging", "development"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"development", "staging", "production"}, lanes)
+	if assert.Len(t, events, 3) {
+		assert.Equal(t, "event-1", events[0].Id)
+		assert.Equal(t, "event-1", events[0].Label)
+		assert.Equal(t, map[string]interface{}{"delta": "abc"}, events[0].Data)
+		assert.Nil(t, events[0].End)
+		assert.Equal(t, "Hotfix", events[1].Label)
+		assert.Equal(t, "d2", events[2].Label)
+		assert.Equal(t, 5*60.0, events[2].End.Sub(events[2].Start).Seconds())
+	}
+}
+
+func TestParseTimelineEvents_invalid(t *testing.T) {
+	for _, tc := range []struct {
+		event map[string]interface{}
+		err   string
+	}{
+		{map[string]interface{}{"start": "2024-01-01T10:00:00Z"}, "event 0 has no lane"},
+		{map[string]interface{}{"lane": "dev", "start": "yesterday"}, `event 0 has an invalid start time: parsing time "yesterday" as "2006-01-02T15:04:05Z07:00": cannot parse "yesterday" as "2006"`},
+		{map[string]interface{}{"lane": "dev", "start": "2024-01-01T10:00:00Z", "end": "2024-01-01T09:00:00Z"}, "event 0 ends before it starts"},
+	} {
+		_, _, err := parseTimelineEvents([]interface{}{tc.event}, nil)
+		assert.EqualError(t, err, tc.err)
+	}
+}
+
+func TestRenderTimeline(t *testing.T) {
+	store := render.NewMemoryStore()
+	previous := renderStore
+	renderStore = func() (render.RenderStore, error) { return store, nil }
+	t.Cleanup(func() { renderStore = previous })
+
+	out, err := NewRenderTimeline().Callable(context.Background(), map[string]interface{}{
+		"title": "Deployments of my-app",
+		"slug":  "my-app-deployments",
+		"events": []interface{}{
+			map[string]interface{}{"id": "d1", "lane": "development", "start": "2024-01-01T10:00:00Z", "end": "2024-01-01T10:02:00Z", "status": "succeeded"},
+		},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, out, 1) {
+		assert.Equal(t, "Timeline rendered and uploaded: memory://my-app-deployments.html (resource canyon://renders/my-app-deployments.html)", out[0].TextContent.Text)
+	}
+	o, ok := store.Get("my-app-deployments.html")
+	assert.True(t, ok)
+	assert.Equal(t, "text/html", o.ContentType)
+	assert.Contains(t, string(o.Content), `"lanes":["development"]`)
+	assert.Contains(t, string(o.Content), `"start":"2024-01-01T10:00:00Z"`)
+
+	_, err = NewRenderTimeline().Callable(context.Background(), map[string]interface{}{"events": []interface{}{"nope"}})
+	assert.EqualError(t, err, "invalid timeline: event 0 is not an object")
+}
@@ -0,0 +1,303 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/humanitec/humanitec-go-autogen/client"
+
+	"github.com/humanitec/canyon-cli/internal"
+	"github.com/humanitec/canyon-cli/internal/clients/humanitec"
+	"github.com/humanitec/canyon-cli/internal/mcp"
+	"github.com/humanitec/canyon-cli/internal/ref"
+)
+
+const defaultResourceClass = "default"
+
+// resourceContext is the tuple that the platform orchestrator uses to select a resource definition.
+type resourceContext struct {
+	ResType string `json:"res_type"`
+	Class   string `json:"class"`
+	ResId   string `json:"res_id,omitempty"`
+	AppId   string `json:"app_id,omitempty"`
+	EnvId   string `json:"env_id,omitempty"`
+	EnvType string `json:"env_type,omitempty"`
+}
+
+type matchingCriteria struct {
+	Id      string `json:"id"`
+	Class   string `json:"class,omitempty"`
+	ResId   string `json:"res_id,omitempty"`
+	AppId   string `json:"app_id,omitempty"`
+	EnvId   string `json:"env_id,omitempty"`
+	EnvType string `json:"env_type,omitempty"`
+}
+
+func newMatchingCriteria(c client.MatchingCriteriaResponse) matchingCriteria {
+	return matchingCriteria{
+		Id:      c.Id,
+		Class:   c.Class,
+		ResId:   ref.Deref(c.ResId, ""),
+		AppId:   ref.Deref(c.AppId, ""),
+		EnvId:   ref.Deref(c.EnvId, ""),
+		EnvType: ref.Deref(c.EnvType, ""),
+	}
+}
+
+// specificity ranks matching criteria in the same order as the platform orchestrator: res_id is the most specific
+// element followed by env_id, env_type, app_id, and finally a non-default class.
+func (c matchingCriteria) specificity() int {
+	score := 0
+	for _, e := range []struct {
+		set    bool
+		weight int
+	}{
+		{c.ResId != "", 16},
+		{c.EnvId != "", 8},
+		{c.EnvType != "", 4},
+		{c.AppId != "", 2},
+		{c.Class != "" && c.Class != defaultResourceClass, 1},
+	} {
+		if e.set {
+			score += e.weight
+		}
+	}
+	return score
+}
+
+// mismatches returns the reasons why the criteria does not match the resource context, or nothing if it matches.
+func (c matchingCriteria) mismatches(rc resourceContext) []string {
+	out := make([]string, 0)
+	class := ref.Coalesce(c.Class, defaultResourceClass)
+	if class != ref.Coalesce(rc.Class, defaultResourceClass) {
+		out = append(out, fmt.Sprintf("requires class '%s'", class))
+	}
+	for _, e := range []struct{ name, want, got string }{
+		{"res_id", c.ResId, rc.ResId},
+		{"app_id", c.AppId, rc.AppId},
+		{"env_id", c.EnvId, rc.EnvId},
+		{"env_type", c.EnvType, rc.EnvType},
+	} {
+		if e.want != "" && e.want != e.got {
+			out = append(out, fmt.Sprintf("requires %s '%s'", e.name, e.want))
+		}
+	}
+	return out
+}
+
+type definitionCandidate struct {
+	DefId       string             `json:"def_id"`
+	Name        string             `json:"name"`
+	DriverType  string             `json:"driver_type"`
+	IsDefault   bool               `json:"is_default,omitempty"`
+	Criteria    []matchingCriteria `json:"criteria,omitempty"`
+	Matched     *matchingCriteria  `json:"matched_criteria,omitempty"`
+	Specificity int                `json:"specificity,omitempty"`
+	Outcome     string             `json:"outcome"`
+
+	fallback bool
+}
+
+type definitionMatchExplanation struct {
+	Context    resourceContext       `json:"context"`
+	Winner     *definitionCandidate  `json:"winner,omitempty"`
+	Candidates []definitionCandidate `json:"candidates"`
+}
+
+// explainDefinitionMatch evaluates the criteria of each resource definition against the resource context and
+// explains which definition is selected and why the others lost. Definitions without matching criteria are only used
+// as a fallback when they are the built-in default definitions and the class is the default class.
+func explainDefinitionMatch(defs []client.ResourceDefinitionResponse, rc resourceContext) definitionMatchExplanation {
+	out := definitionMatchExplanation{Context: rc, Candidates: make([]definitionCandidate, 0, len(defs))}
+	for _, def := range defs {
+		if def.Type != rc.ResType || def.IsDeleted {
+			continue
+		}
+		candidate := definitionCandidate{DefId: def.Id, Name: def.Name, DriverType: def.DriverType, IsDefault: def.IsDefault, Specificity: -1}
+		reasons := make([]string, 0)
+		for _, c := range ref.Deref(def.Criteria, nil) {
+			mc := newMatchingCriteria(c)
+			candidate.Criteria = append(candidate.Criteria, mc)
+			if m := mc.mismatches(rc); len(m) > 0 {
+				reasons = append(reasons, fmt.Sprintf("criteria %s %s", mc.Id, strings.Join(m, " and ")))
+			} else if s := mc.specificity(); s > candidate.Specificity {
+				candidate.Matched = &mc
+				candidate.Specificity = s
+			}
+		}
+		if candidate.Matched == nil {
+			switch {
+			case len(candidate.Criteria) == 0 && def.IsDefault && ref.Coalesce(rc.Class, defaultResourceClass) == defaultResourceClass:
+				candidate.fallback = true
+				candidate.Outcome = "default definition used only if no other definition matches"
+			case len(candidate.Criteria) == 0:
+				candidate.Outcome = "not matched: the definition has no matching criteria"
+			default:
+				candidate.Outcome = "not matched: " + strings.Join(reasons, "; ")
+			}
+		}
+		out.Candidates = append(out.Candidates, candidate)
+	}
+
+	slices.SortStableFunc(out.Candidates, func(a, b definitionCandidate) int {
+		return b.Specificity - a.Specificity
+	})
+
+	winner := -1
+	if len(out.Candidates) > 0 && out.Candidates[0].Matched != nil {
+		winner = 0
+	} else if i := slices.IndexFunc(out.Candidates, func(c definitionCandidate) bool {
+		return c.fallback
+	}); i >= 0 {
+		winner = i
+	}
+	for i := range out.Candidates {
+		c := &out.Candidates[i]
+		switch {
+		case i == winner && c.Matched != nil:
+			c.Outcome = fmt.Sprintf("selected: criteria %s is the most specific match", c.Matched.Id)
+		case i == winner:
+			c.Outcome = "selected: no definition has matching criteria so the default definition is used"
+		case c.Matched != nil && c.Specificity == out.Candidates[winner].Specificity:
+			c.Outcome = fmt.Sprintf("ambiguous: criteria %s is as specific as the selected definition", c.Matched.Id)
+		case c.Matched != nil:
+			c.Outcome = fmt.Sprintf("lost: criteria %s matches but is less specific than the selected definition", c.Matched.Id)
+		}
+		if c.Specificity < 0 {
+			c.Specificity = 0
+		}
+	}
+	if winner >= 0 {
+		out.Winner = ref.Ref(out.Candidates[winner])
+	}
+	return out
+}
+
+func listResourceDefinitions(ctx context.Context, hc *humanitec.WrappedHumanitecClientImpl, orgId string, params *client.ListResourceDefinitionsParams) ([]client.ResourceDefinitionResponse, error) {
+	r, err := humanitec.CheckResponse(func() (*client.ListResourceDefinitionsResponse, error) {
+		return hc.ListResourceDefinitionsWithResponse(ctx, orgId, params)
+	}).AndStatusCodeEq(http.StatusOK).RespAndError()
+	if err != nil {
+		return nil, err
+	}
+	return *r.JSON200, nil
+}
+
+func NewListResourceDefinitions() mcp.Tool {
+	return mcp.Tool{
+		Name:        "list_humanitec_resource_definitions",
+		Description: `This tool lists the Resource Definitions in a Humanitec Organization including their resource type, driver, and matching criteria. An optional res_type argument filters by resource type.`,
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"org_id":   map[string]interface{}{"type": "string", "description": "The Humanitec Organization (org) ID to work with."},
+				"res_type": map[string]interface{}{"type": "string", "description": "Optional filter for a specific resource type, for example postgres or dns"},
+			},
+			"required":             []string{"org_id"},
+			"additionalProperties": false,
+		},
+		Callable: func(ctx context.Context, m map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+			orgId, _ := m["org_id"].(string)
+			params := &client.ListResourceDefinitionsParams{}
+			if v, _ := m["res_type"].(string); v != "" {
+				params.ResType = &v
+			}
+			hc, err := humanitec.NewHumanitecClientWithCurrentToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+			defs, err := listResourceDefinitions(ctx, hc, orgId, params)
+			if err != nil {
+				return nil, err
+			}
+
+			type definition struct {
+				Id         string             `json:"id"`
+				Name       string             `json:"name"`
+				Type       string             `json:"type"`
+				DriverType string             `json:"driver_type"`
+				IsDefault  bool               `json:"is_default,omitempty"`
+				Criteria   []matchingCriteria `json:"criteria"`
+			}
+			out := make([]definition, 0, len(defs))
+			for _, d := range defs {
+				od := definition{Id: d.Id, Name: d.Name, Type: d.Type, DriverType: d.DriverType, IsDefault: d.IsDefault, Criteria: make([]matchingCriteria, 0)}
+				for _, c := range ref.Deref(d.Criteria, nil) {
+					od.Criteria = append(od.Criteria, newMatchingCriteria(c))
+				}
+				out = append(out, od)
+			}
+			return []mcp.CallToolResponseContent{
+				mcp.NewTextToolResponseContent("The Organization '%s' has the following Resource Definitions in JSON format: %s", orgId, internal.PrettyJson(out)),
+			}, nil
+		},
+	}
+}
+
+func NewExplainResourceDefinitionMatching() mcp.Tool {
+	return mcp.Tool{
+		Name: "explain_humanitec_resource_definition_matching",
+		Description: `This tool evaluates which Humanitec Resource Definition will be selected for a resource with the given type, class, resource id, application, and environment.
+It explains why the selected definition won and why the other candidate definitions lost. Use this to answer questions like "which resource definition will this workload get in environment X".
+If env_type is not provided but app_id and env_id are, the environment type is looked up.`,
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"org_id":   map[string]interface{}{"type": "string", "description": "The Humanitec Organization (org) ID to work with."},
+				"res_type": map[string]interface{}{"type": "string", "description": "The resource type, for example postgres or dns"},
+				"class":    map[string]interface{}{"type": "string", "description": "The resource class, defaults to 'default'"},
+				"res_id":   map[string]interface{}{"type": "string", "description": "Optional resource id, for example modules.my-workload.externals.my-db or shared.my-dns"},
+				"app_id":   map[string]interface{}{"type": "string", "description": "Optional Humanitec Application (app) ID"},
+				"env_id":   map[string]interface{}{"type": "string", "description": "Optional Humanitec Environment (env) ID"},
+				"env_type": map[string]interface{}{"type": "string", "description": "Optional Humanitec Environment Type"},
+			},
+			"required":             []string{"org_id", "res_type"},
+			"additionalProperties": false,
+		},
+		Callable: func(ctx context.Context, m map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+			orgId, _ := m["org_id"].(string)
+			rc := resourceContext{Class: defaultResourceClass}
+			rc.ResType, _ = m["res_type"].(string)
+			if v, _ := m["class"].(string); v != "" {
+				rc.Class = v
+			}
+			rc.ResId, _ = m["res_id"].(string)
+			rc.AppId, _ = m["app_id"].(string)
+			rc.EnvId, _ = m["env_id"].(string)
+			rc.EnvType, _ = m["env_type"].(string)
+
+			hc, err := humanitec.NewHumanitecClientWithCurrentToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if rc.EnvType == "" && rc.AppId != "" && rc.EnvId != "" {
+				if r, err := humanitec.CheckResponse(func() (*client.GetEnvironmentResponse, error) {
+					return hc.GetEnvironmentWithResponse(ctx, orgId, rc.AppId, rc.EnvId)
+				}).AndStatusCodeEq(http.StatusOK).RespAndError(); err != nil {
+					return nil, err
+				} else {
+					rc.EnvType = r.JSON200.Type
+				}
+			}
+
+			defs, err := listResourceDefinitions(ctx, hc, orgId, &client.ListResourceDefinitionsParams{ResType: &rc.ResType})
+			if err != nil {
+				return nil, err
+			}
+			out := explainDefinitionMatch(defs, rc)
+			summary := fmt.Sprintf("No Resource Definition of type '%s' matches class '%s' in this context, provisioning the resource would fail.", rc.ResType, rc.Class)
+			if out.Winner != nil && out.Winner.Matched != nil {
+				summary = fmt.Sprintf("Resource Definition '%s' (driver %s) will be selected because its criteria %s is the most specific match.", out.Winner.DefId, out.Winner.DriverType, out.Winner.Matched.Id)
+			} else if out.Winner != nil {
+				summary = fmt.Sprintf("Resource Definition '%s' (driver %s) will be selected because no other definition has matching criteria.", out.Winner.DefId, out.Winner.DriverType)
+			}
+			return []mcp.CallToolResponseContent{
+				mcp.NewTextToolResponseContent("%s", summary),
+				mcp.NewTextToolResponseContent("The evaluation of every candidate definition in JSON format: %s", internal.PrettyJson(out)),
+			}, nil
+		},
+	}
+}
@@ -0,0 +1,50 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/humanitec/humanitec-go-autogen/client"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/humanitec/canyon-cli/internal/ref"
+)
+
+func TestExplainDefinitionMatch(t *testing.T) {
+	defs := []client.ResourceDefinitionResponse{
+		{Id: "default-postgres", Type: "postgres", DriverType: "humanitec/postgres-cloudsql-static", IsDefault: true},
+		{Id: "dev-postgres", Type: "postgres", DriverType: "humanitec/template", Criteria: &[]client.MatchingCriteriaResponse{
+			{Id: "c1", Class: "default", EnvType: ref.Ref("development")},
+		}},
+		{Id: "app-postgres", Type: "postgres", DriverType: "humanitec/template", Criteria: &[]client.MatchingCriteriaResponse{
+			{Id: "c2", Class: "default", AppId: ref.Ref("my-app")},
+		}},
+		{Id: "large-postgres", Type: "postgres", DriverType: "humanitec/template", Criteria: &[]client.MatchingCriteriaResponse{
+			{Id: "c3", Class: "large"},
+		}},
+		{Id: "dns", Type: "dns", DriverType: "humanitec/dns-cloudflare", Criteria: &[]client.MatchingCriteriaResponse{{Id: "c4"}}},
+	}
+
+	t.Run("most specific wins", func(t *testing.T) {
+		out := explainDefinitionMatch(defs, resourceContext{ResType: "postgres", Class: "default", AppId: "my-app", EnvType: "development"})
+		assert.Len(t, out.Candidates, 4)
+		if assert.NotNil(t, out.Winner) {
+			assert.Equal(t, "dev-postgres", out.Winner.DefId)
+		}
+		assert.Equal(t, "app-postgres", out.Candidates[1].DefId)
+		assert.Contains(t, out.Candidates[1].Outcome, "less specific")
+		assert.Equal(t, "not matched: criteria c3 requires class 'large'", out.Candidates[3].Outcome)
+	})
+
+	t.Run("falls back to default definition", func(t *testing.T) {
+		out := explainDefinitionMatch(defs, resourceContext{ResType: "postgres", Class: "default", AppId: "other", EnvType: "production"})
+		if assert.NotNil(t, out.Winner) {
+			assert.Equal(t, "default-postgres", out.Winner.DefId)
+		}
+		assert.Contains(t, out.Candidates[1].Outcome, "requires env_type 'development'")
+	})
+
+	t.Run("no match for unknown class", func(t *testing.T) {
+		out := explainDefinitionMatch(defs, resourceContext{ResType: "postgres", Class: "small"})
+		assert.Nil(t, out.Winner)
+	})
+}
@@ -0,0 +1,178 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/humanitec/humanitec-go-autogen/client"
+
+	"github.com/humanitec/canyon-cli/internal"
+	"github.com/humanitec/canyon-cli/internal/clients/humanitec"
+	"github.com/humanitec/canyon-cli/internal/mcp"
+	"github.com/humanitec/canyon-cli/internal/ref"
+)
+
+type activeResource struct {
+	Type          string    `json:"type"`
+	Class         string    `json:"class"`
+	ResId         string    `json:"resId"`
+	GuResId       string    `json:"guResId"`
+	DefId         string    `json:"defId"`
+	DefVersionId  string    `json:"defVersionId,omitempty"`
+	DriverType    string    `json:"driverType"`
+	DriverAccount string    `json:"driverAccount,omitempty"`
+	Status        string    `json:"status"`
+	DeployId      string    `json:"deployId"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// graphNode and graphLink match the input schema of the render_network_as_graph tool so that the resource graph can
+// be passed to it without conversion.
+type graphNode struct {
+	Id    string                 `json:"id"`
+	Class string                 `json:"class"`
+	Data  map[string]interface{} `json:"data,omitempty"`
+}
+
+type graphLink struct {
+	Source      string `json:"source"`
+	Target      string `json:"target"`
+	Explanation string `json:"explanation,omitempty"`
+}
+
+type resourceGraph struct {
+	Nodes []graphNode `json:"nodes"`
+	Links []graphLink `json:"links"`
+}
+
+func resourceGraphNodeId(resType, class, resId string) string {
+	return fmt.Sprintf("%s.%s (%s)", resType, class, resId)
+}
+
+// buildResourceGraph converts the resource graph nodes into the network graph schema. Resources that belong to a
+// workload (modules.<workload>.externals.<name>) are also linked to a node for that workload. Dependencies that are not
+// among the nodes are linked to a placeholder node for their guresid, so that every link can be rendered.
+func buildResourceGraph(nodes []client.NodeBodyResponse) resourceGraph {
+	ids := make(map[string]string, len(nodes))
+	for _, n := range nodes {
+		ids[n.Guresid] = resourceGraphNodeId(n.Type, n.Class, n.Id)
+	}
+	out := resourceGraph{Nodes: make([]graphNode, 0, len(nodes)), Links: make([]graphLink, 0)}
+	workloads := make(map[string]bool)
+	unresolved := make(map[string]bool)
+	for _, n := range nodes {
+		id := ids[n.Guresid]
+		out.Nodes = append(out.Nodes, graphNode{Id: id, Class: "resource", Data: map[string]interface{}{
+			"type":        n.Type,
+			"class":       n.Class,
+			"res_id":      n.Id,
+			"def_id":      n.DefId,
+			"driver_type": n.DriverType,
+		}})
+		for _, dep := range n.DependsOn {
+			target, ok := ids[dep]
+			if !ok {
+				target = dep
+				if !unresolved[dep] {
+					unresolved[dep] = true
+					out.Nodes = append(out.Nodes, graphNode{Id: dep, Class: "other", Data: map[string]interface{}{
+						"guresid":    dep,
+						"unresolved": "The resource is not in the resource graph of the Environment",
+					}})
+				}
+			}
+			out.Links = append(out.Links, graphLink{Source: id, Target: target, Explanation: "depends on"})
+		}
+		if parts := strings.Split(n.Id, "."); len(parts) >= 3 && parts[0] == "modules" {
+			if !workloads[parts[1]] {
+				workloads[parts[1]] = true
+				out.Nodes = append(out.Nodes, graphNode{Id: parts[1], Class: "workload"})
+			}
+			out.Links = append(out.Links, graphLink{Source: parts[1], Target: id, Explanation: "uses"})
+		}
+	}
+	return out
+}
+
+func NewListActiveResources() mcp.Tool {
+	return mcp.Tool{
+		Name: "list_humanitec_active_resources",
+		Description: `This tool returns the active resources provisioned in a Humanitec Environment including their resource type, class, resource definition, and driver.
+It can also return the resource dependency graph of the Environment as nodes and links which can be passed directly to the render_network_as_graph_to_minio tool.`,
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"org_id":        map[string]interface{}{"type": "string", "description": "The Humanitec Organization (org) ID to work with."},
+				"app_id":        map[string]interface{}{"type": "string", "description": "The Humanitec Application (app) ID to work with."},
+				"env_id":        map[string]interface{}{"type": "string", "description": "The Humanitec Environment (env) ID to work with."},
+				"res_type":      map[string]interface{}{"type": "string", "description": "Optional filter for a specific resource type, for example postgres or dns"},
+				"include_graph": map[string]interface{}{"type": "boolean", "description": "Whether to also return the resource dependency graph"},
+			},
+			"required":             []string{"org_id", "app_id", "env_id"},
+			"additionalProperties": false,
+		},
+		Callable: func(ctx context.Context, m map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+			orgId, _ := m["org_id"].(string)
+			appId, _ := m["app_id"].(string)
+			envId, _ := m["env_id"].(string)
+			resTypeFilter, _ := m["res_type"].(string)
+			includeGraph, _ := m["include_graph"].(bool)
+
+			hc, err := humanitec.NewHumanitecClientWithCurrentToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+			r, err := humanitec.CheckResponse(func() (*client.ListActiveResourcesResponse, error) {
+				return hc.ListActiveResourcesWithResponse(ctx, orgId, appId, envId, &client.ListActiveResourcesParams{})
+			}).AndStatusCodeEq(http.StatusOK).RespAndError()
+			if err != nil {
+				return nil, err
+			}
+
+			resources := make([]activeResource, 0)
+			for _, ar := range *r.JSON200 {
+				if resTypeFilter != "" && ar.Type != resTypeFilter {
+					continue
+				}
+				resources = append(resources, activeResource{
+					Type:          ar.Type,
+					Class:         ar.Class,
+					ResId:         ar.ResId,
+					GuResId:       ar.GuResId,
+					DefId:         ar.DefId,
+					DefVersionId:  ar.DefVersionId,
+					DriverType:    ar.DriverType,
+					DriverAccount: ref.Deref(ar.DriverAccount, ""),
+					Status:        ar.Status,
+					DeployId:      ar.DeployId,
+					UpdatedAt:     ar.UpdatedAt,
+				})
+			}
+			slices.SortFunc(resources, func(a, b activeResource) int {
+				return strings.Compare(a.ResId+a.Type, b.ResId+b.Type)
+			})
+
+			out := []mcp.CallToolResponseContent{
+				mcp.NewTextToolResponseContent("The Environment '%s' of Application '%s' has the following active resources in JSON format: %s", envId, appId, internal.PrettyJson(resources)),
+			}
+			if includeGraph && len(resources) > 0 {
+				body := make(client.QueryResourceGraphJSONRequestBody, 0, len(resources))
+				for _, ar := range resources {
+					body = append(body, client.ResourceProvisionRequestRequest{Id: ar.ResId, Type: ar.Type, Class: ref.Ref(ar.Class)})
+				}
+				gr, err := humanitec.CheckResponse(func() (*client.QueryResourceGraphResponse, error) {
+					return hc.QueryResourceGraphWithResponse(ctx, orgId, appId, envId, body)
+				}).AndStatusCodeEq(http.StatusOK).RespAndError()
+				if err != nil {
+					return append(out, mcp.NewTextToolResponseContent("Failed to fetch the resource graph: %v", err.Error())), nil
+				}
+				out = append(out, mcp.NewTextToolResponseContent("The resource dependency graph in the network graph render format in JSON: %s", internal.PrettyJson(buildResourceGraph(*gr.JSON200))))
+			}
+			return out, nil
+		},
+	}
+}
@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/humanitec/humanitec-go-autogen/client"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/humanitec/canyon-cli/internal/diagram"
+)
+
+func TestBuildResourceGraph(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		nodes []client.NodeBodyResponse
+		ids   []string
+		links []graphLink
+	}{
+		{
+			name:  "empty",
+			ids:   []string{},
+			links: []graphLink{},
+		},
+		{
+			name: "resolved dependency and workload",
+			nodes: []client.NodeBodyResponse{
+				{Guresid: "g1", Type: "postgres", Class: "default", Id: "modules.api.externals.db", DependsOn: []string{"g2"}},
+				{Guresid: "g2", Type: "k8s-cluster", Class: "default", Id: "k8s-cluster"},
+			},
+			ids: []string{"postgres.default (modules.api.externals.db)", "api", "k8s-cluster.default (k8s-cluster)"},
+			links: []graphLink{
+				{Source: "postgres.default (modules.api.externals.db)", Target: "k8s-cluster.default (k8s-cluster)", Explanation: "depends on"},
+				{Source: "api", Target: "postgres.default (modules.api.externals.db)", Explanation: "uses"},
+			},
+		},
+		{
+			name: "unresolved dependency gets a single placeholder",
+			nodes: []client.NodeBodyResponse{
+				{Guresid: "g1", Type: "dns", Class: "default", Id: "shared.dns", DependsOn: []string{"missing"}},
+				{Guresid: "g2", Type: "route", Class: "default", Id: "shared.route", DependsOn: []string{"missing", "g1"}},
+			},
+			ids: []string{"dns.default (shared.dns)", "missing", "route.default (shared.route)"},
+			links: []graphLink{
+				{Source: "dns.default (shared.dns)", Target: "missing", Explanation: "depends on"},
+				{Source: "route.default (shared.route)", Target: "missing", Explanation: "depends on"},
+				{Source: "route.default (shared.route)", Target: "dns.default (shared.dns)", Explanation: "depends on"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := buildResourceGraph(tc.nodes)
+			ids := make([]string, 0, len(g.Nodes))
+			dg := diagram.Graph{}
+			for _, n := range g.Nodes {
+				ids = append(ids, n.Id)
+				dg.Nodes = append(dg.Nodes, diagram.Node{Id: n.Id, Class: n.Class})
+			}
+			for _, l := range g.Links {
+				dg.Links = append(dg.Links, diagram.Link{Source: l.Source, Target: l.Target})
+			}
+			assert.Equal(t, tc.ids, ids)
+			assert.Equal(t, tc.links, g.Links)
+			// the graph can be fed straight into the renderer
+			_, err := diagram.LayoutGraph(dg)
+			assert.NoError(t, err)
+		})
+	}
+}
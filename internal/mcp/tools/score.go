@@ -0,0 +1,503 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/humanitec/humanitec-go-autogen/client"
+	"gopkg.in/yaml.v3"
+
+	"github.com/humanitec/canyon-cli/internal"
+	"github.com/humanitec/canyon-cli/internal/clients/humanitec"
+	"github.com/humanitec/canyon-cli/internal/mcp"
+	"github.com/humanitec/canyon-cli/internal/ref"
+)
+
+const (
+	scoreApiVersion        = "score.dev/v1b1"
+	defaultWorkloadProfile = "humanitec/default-module"
+)
+
+// scoreSchema is a minimal structural schema used to validate Score files offline. Objects have a fixed set of
+// properties, unless they are open to additional properties, maps have arbitrary keys with values of the same schema,
+// and arrays have items of the same schema.
+type scoreSchema struct {
+	kind     string
+	required []string
+	props    map[string]*scoreSchema
+	open     bool
+	values   *scoreSchema
+	enum     []string
+	pattern  *regexp.Regexp
+	minItems int
+}
+
+func scoreString() *scoreSchema                  { return &scoreSchema{kind: "string"} }
+func scoreInteger() *scoreSchema                 { return &scoreSchema{kind: "integer"} }
+func scoreBoolean() *scoreSchema                 { return &scoreSchema{kind: "boolean"} }
+func scoreAny() *scoreSchema                     { return &scoreSchema{kind: "any"} }
+func scoreMap(values *scoreSchema) *scoreSchema  { return &scoreSchema{kind: "map", values: values} }
+func scoreArray(items *scoreSchema) *scoreSchema { return &scoreSchema{kind: "array", values: items} }
+func scoreEnum(values ...string) *scoreSchema    { return &scoreSchema{kind: "string", enum: values} }
+func scoreObject(props map[string]*scoreSchema, required ...string) *scoreSchema {
+	return &scoreSchema{kind: "object", props: props, required: required}
+}
+
+// scoreOpenObject is an object that allows additional properties besides the known ones.
+func scoreOpenObject(props map[string]*scoreSchema, required ...string) *scoreSchema {
+	return &scoreSchema{kind: "object", props: props, required: required, open: true}
+}
+
+var scoreWorkloadSchema = func() *scoreSchema {
+	httpProbe := scoreObject(map[string]*scoreSchema{
+		"host":        scoreString(),
+		"scheme":      scoreEnum("HTTP", "HTTPS"),
+		"path":        scoreString(),
+		"port":        scoreInteger(),
+		"httpHeaders": scoreArray(scoreObject(map[string]*scoreSchema{"name": scoreString(), "value": scoreString()}, "name", "value")),
+	}, "path", "port")
+	probe := scoreObject(map[string]*scoreSchema{
+		"httpGet": httpProbe,
+		"exec":    scoreObject(map[string]*scoreSchema{"command": scoreArray(scoreString())}, "command"),
+	})
+	resourceLimits := scoreObject(map[string]*scoreSchema{"memory": scoreString(), "cpu": scoreString()})
+	container := scoreObject(map[string]*scoreSchema{
+		"image":     scoreString(),
+		"command":   scoreArray(scoreString()),
+		"args":      scoreArray(scoreString()),
+		"variables": scoreMap(scoreString()),
+		"files": scoreArray(scoreObject(map[string]*scoreSchema{
+			"target":   scoreString(),
+			"mode":     scoreString(),
+			"source":   scoreString(),
+			"content":  scoreString(),
+			"noExpand": scoreBoolean(),
+		}, "target")),
+		"volumes": scoreArray(scoreObject(map[string]*scoreSchema{
+			"source":   scoreString(),
+			"path":     scoreString(),
+			"target":   scoreString(),
+			"readOnly": scoreBoolean(),
+		}, "source", "target")),
+		"resources":      scoreObject(map[string]*scoreSchema{"limits": resourceLimits, "requests": resourceLimits}),
+		"livenessProbe":  probe,
+		"readinessProbe": probe,
+	}, "image")
+	containers := scoreMap(container)
+	containers.minItems = 1
+	return scoreObject(map[string]*scoreSchema{
+		"apiVersion": scoreEnum(scoreApiVersion),
+		"metadata": scoreOpenObject(map[string]*scoreSchema{
+			"name":        {kind: "string", pattern: regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,61}[a-z0-9]$`)},
+			"annotations": scoreMap(scoreString()),
+		}, "name"),
+		"service": scoreObject(map[string]*scoreSchema{
+			"ports": scoreMap(scoreObject(map[string]*scoreSchema{
+				"port":       scoreInteger(),
+				"protocol":   scoreEnum("TCP", "UDP"),
+				"targetPort": scoreInteger(),
+			}, "port")),
+		}),
+		"containers": containers,
+		"resources": scoreMap(scoreObject(map[string]*scoreSchema{
+			"type":     scoreString(),
+			"class":    scoreString(),
+			"id":       scoreString(),
+			"metadata": scoreOpenObject(map[string]*scoreSchema{"annotations": scoreMap(scoreString())}),
+			"params":   scoreMap(scoreAny()),
+		}, "type")),
+	}, "apiVersion", "metadata", "containers")
+}()
+
+type scoreIssue struct {
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (s *scoreSchema) validate(node *yaml.Node, path string, out []scoreIssue) []scoreIssue {
+	issue := func(n *yaml.Node, format string, args ...interface{}) {
+		out = append(out, scoreIssue{Line: n.Line, Column: n.Column, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	switch s.kind {
+	case "any":
+	case "string", "integer", "boolean":
+		if node.Kind != yaml.ScalarNode {
+			issue(node, "expected a %s", s.kind)
+			break
+		}
+		switch {
+		case s.kind == "integer" && node.Tag != "!!int":
+			issue(node, "expected an integer but found '%s'", node.Value)
+		case s.kind == "boolean" && node.Tag != "!!bool":
+			issue(node, "expected a boolean but found '%s'", node.Value)
+		case s.kind == "string" && node.Tag == "!!null":
+			issue(node, "expected a string but found null")
+		case len(s.enum) > 0 && !slices.Contains(s.enum, node.Value):
+			issue(node, "expected one of %s but found '%s'", strings.Join(s.enum, ", "), node.Value)
+		case s.pattern != nil && !s.pattern.MatchString(node.Value):
+			issue(node, "'%s' does not match the pattern %s", node.Value, s.pattern.String())
+		}
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			issue(node, "expected an array")
+			break
+		}
+		for i, item := range node.Content {
+			out = s.values.validate(item, fmt.Sprintf("%s[%d]", path, i), out)
+		}
+	case "map", "object":
+		if node.Kind != yaml.MappingNode {
+			issue(node, "expected an object")
+			break
+		}
+		seen := make([]string, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			seen = append(seen, key.Value)
+			if s.kind == "map" {
+				out = s.values.validate(value, path+"."+key.Value, out)
+			} else if ps, ok := s.props[key.Value]; ok {
+				out = ps.validate(value, path+"."+key.Value, out)
+			} else if !s.open {
+				out = append(out, scoreIssue{Line: key.Line, Column: key.Column, Path: path + "." + key.Value, Message: fmt.Sprintf("unknown property '%s'", key.Value)})
+			}
+		}
+		for _, r := range s.required {
+			if !slices.Contains(seen, r) {
+				issue(node, "missing required property '%s'", r)
+			}
+		}
+		if len(seen) < s.minItems {
+			issue(node, "expected at least %d entries", s.minItems)
+		}
+	}
+	return out
+}
+
+var scorePlaceholderPattern = regexp.MustCompile(`\$\{([^}]*)}`)
+
+// scorePlaceholders returns the submatch indexes of the placeholders in the value. Placeholders escaped as $${...} are
+// literal text in Score and are skipped.
+func scorePlaceholders(value string) [][]int {
+	out := make([][]int, 0)
+	for _, m := range scorePlaceholderPattern.FindAllStringSubmatchIndex(value, -1) {
+		dollars := 0
+		for i := m[0] - 1; i >= 0 && value[i] == '$'; i-- {
+			dollars++
+		}
+		if dollars%2 == 0 {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// validateScorePlaceholders checks that placeholders reference metadata or declared resources.
+func validateScorePlaceholders(node *yaml.Node, resources []string, path string, out []scoreIssue) []scoreIssue {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, c := range node.Content {
+			out = validateScorePlaceholders(c, resources, path, out)
+		}
+	case yaml.SequenceNode:
+		for i, c := range node.Content {
+			out = validateScorePlaceholders(c, resources, fmt.Sprintf("%s[%d]", path, i), out)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			out = validateScorePlaceholders(node.Content[i+1], resources, path+"."+node.Content[i].Value, out)
+		}
+	case yaml.ScalarNode:
+		for _, m := range scorePlaceholders(node.Value) {
+			match := []string{node.Value[m[0]:m[1]], node.Value[m[2]:m[3]]}
+			parts := strings.Split(match[1], ".")
+			switch {
+			case parts[0] == "metadata" && len(parts) >= 2:
+			case parts[0] == "resources" && len(parts) >= 2:
+				if !slices.Contains(resources, parts[1]) {
+					out = append(out, scoreIssue{Line: node.Line, Column: node.Column, Path: path, Message: fmt.Sprintf("placeholder '%s' references undeclared resource '%s'", match[0], parts[1])})
+				}
+			default:
+				out = append(out, scoreIssue{Line: node.Line, Column: node.Column, Path: path, Message: fmt.Sprintf("placeholder '%s' must reference metadata or resources", match[0])})
+			}
+		}
+	}
+	return out
+}
+
+// convertScorePlaceholders rewrites Score placeholders into the Humanitec deployment set placeholder syntax.
+// Placeholders escaped as $${...} are left as they are.
+func convertScorePlaceholders(value string, shared map[string]string, environmentResources map[string]bool) string {
+	convert := func(s string) string {
+		parts := strings.Split(s[2:len(s)-1], ".")
+		if parts[0] != "resources" || len(parts) < 2 {
+			return s
+		}
+		switch {
+		case environmentResources[parts[1]]:
+			return "${values." + strings.Join(parts[2:], ".") + "}"
+		case shared[parts[1]] != "":
+			return "${shared." + strings.Join(append([]string{shared[parts[1]]}, parts[2:]...), ".") + "}"
+		default:
+			return "${externals." + strings.Join(parts[1:], ".") + "}"
+		}
+	}
+	b := new(strings.Builder)
+	last := 0
+	for _, m := range scorePlaceholders(value) {
+		b.WriteString(value[last:m[0]])
+		b.WriteString(convert(value[m[0]:m[1]]))
+		last = m[1]
+	}
+	b.WriteString(value[last:])
+	return b.String()
+}
+
+// previewScoreModule approximates offline the deployment set module and shared resources that the platform
+// orchestrator produces for a Score workload. The authoritative conversion requires the Humanitec API.
+func previewScoreModule(score map[string]interface{}, profile string) map[string]interface{} {
+	metadata, _ := score["metadata"].(map[string]interface{})
+	resources, _ := score["resources"].(map[string]interface{})
+	shared, environmentResources := make(map[string]string), make(map[string]bool)
+	externals, sharedOut := make(map[string]interface{}), make(map[string]interface{})
+	for name, raw := range resources {
+		r, _ := raw.(map[string]interface{})
+		out := map[string]interface{}{"type": r["type"]}
+		for _, k := range []string{"class", "params"} {
+			if v, ok := r[k]; ok {
+				out[k] = v
+			}
+		}
+		switch {
+		case r["type"] == "environment":
+			environmentResources[name] = true
+		case r["id"] != nil:
+			shared[name] = fmt.Sprint(r["id"])
+			sharedOut[shared[name]] = out
+		default:
+			externals[name] = out
+		}
+	}
+
+	containers := make(map[string]interface{})
+	rawContainers, _ := score["containers"].(map[string]interface{})
+	for name, raw := range rawContainers {
+		c, _ := raw.(map[string]interface{})
+		out := map[string]interface{}{"id": name, "image": c["image"]}
+		for _, k := range []string{"command", "args"} {
+			if v, ok := c[k]; ok {
+				out[k] = v
+			}
+		}
+		if vars, ok := c["variables"].(map[string]interface{}); ok {
+			converted := make(map[string]interface{}, len(vars))
+			for k, v := range vars {
+				converted[k] = convertScorePlaceholders(fmt.Sprint(v), shared, environmentResources)
+			}
+			out["variables"] = converted
+		}
+		if files, ok := c["files"].([]interface{}); ok {
+			converted := make(map[string]interface{}, len(files))
+			for _, raw := range files {
+				f, _ := raw.(map[string]interface{})
+				mode, _ := f["mode"].(string)
+				converted[fmt.Sprint(f["target"])] = map[string]interface{}{
+					"mode":  ref.Coalesce(mode, "0644"),
+					"value": convertScorePlaceholders(fmt.Sprint(f["content"]), shared, environmentResources),
+				}
+			}
+			out["files"] = converted
+		}
+		for k, target := range map[string]string{"resources": "resources", "livenessProbe": "liveness_probe", "readinessProbe": "readiness_probe"} {
+			if v, ok := c[k]; ok {
+				out[target] = v
+			}
+		}
+		containers[name] = out
+	}
+
+	spec := map[string]interface{}{"containers": containers}
+	if svc, ok := score["service"].(map[string]interface{}); ok {
+		ports := make(map[string]interface{})
+		rawPorts, _ := svc["ports"].(map[string]interface{})
+		for name, raw := range rawPorts {
+			p, _ := raw.(map[string]interface{})
+			port := map[string]interface{}{"service_port": p["port"], "container_port": p["port"], "protocol": "TCP"}
+			if v, ok := p["targetPort"]; ok {
+				port["container_port"] = v
+			}
+			if v, ok := p["protocol"]; ok {
+				port["protocol"] = v
+			}
+			ports[name] = port
+		}
+		spec["ports"] = ports
+	}
+	if annotations, ok := metadata["annotations"]; ok {
+		spec["annotations"] = annotations
+	}
+
+	return map[string]interface{}{
+		"modules": map[string]interface{}{
+			fmt.Sprint(metadata["name"]): map[string]interface{}{
+				"profile":   profile,
+				"spec":      spec,
+				"externals": externals,
+			},
+		},
+		"shared": sharedOut,
+	}
+}
+
+// validateAgainstProfile reports the module spec properties and container properties that the workload profile
+// schema does not declare.
+func validateAgainstProfile(module map[string]interface{}, profileSchema interface{}) []string {
+	out := make([]string, 0)
+	schema, _ := profileSchema.(map[string]interface{})
+	props, _ := schema["properties"].(map[string]interface{})
+	if props == nil {
+		return out
+	}
+	spec, _ := module["spec"].(map[string]interface{})
+	for k := range spec {
+		if _, ok := props[k]; !ok {
+			out = append(out, fmt.Sprintf("the workload profile does not support the spec property '%s'", k))
+		}
+	}
+	containerSchema, _ := props["containers"].(map[string]interface{})
+	containerItems, _ := containerSchema["additionalProperties"].(map[string]interface{})
+	containerProps, _ := containerItems["properties"].(map[string]interface{})
+	if containerProps != nil {
+		containers, _ := spec["containers"].(map[string]interface{})
+		for name, raw := range containers {
+			c, _ := raw.(map[string]interface{})
+			for k := range c {
+				if _, ok := containerProps[k]; !ok {
+					out = append(out, fmt.Sprintf("the workload profile does not support the property '%s' in container '%s'", k, name))
+				}
+			}
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+func NewValidateScoreWorkload() mcp.Tool {
+	return mcp.Tool{
+		Name: "validate_score_workload",
+		Description: `This tool validates a Score workload specification (score.yaml) and previews the Humanitec deployment set module that it would produce.
+The Score file is validated offline against the Score schema and the errors are reported with line numbers. Placeholders that reference undeclared resources are reported too.
+If org_id is provided, the module is also checked against the workload profile of the organization and the authoritative deployment set is generated by the Humanitec API.`,
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"score":               map[string]interface{}{"type": "string", "description": "The raw Score YAML document"},
+				"org_id":              map[string]interface{}{"type": "string", "description": "Optional Humanitec Organization (org) ID to validate the workload against"},
+				"workload_profile_id": map[string]interface{}{"type": "string", "description": "The workload profile to use, defaults to " + defaultWorkloadProfile},
+				"image":               map[string]interface{}{"type": "string", "description": "Optional image to use for containers with the '.' image placeholder"},
+			},
+			"required":             []string{"score"},
+			"additionalProperties": false,
+		},
+		Callable: func(ctx context.Context, m map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+			raw, _ := m["score"].(string)
+			orgId, _ := m["org_id"].(string)
+			profile, _ := m["workload_profile_id"].(string)
+			if profile == "" {
+				profile = defaultWorkloadProfile
+			}
+			image, _ := m["image"].(string)
+
+			var doc yaml.Node
+			if err := yaml.Unmarshal([]byte(raw), &doc); err != nil {
+				return nil, fmt.Errorf("the score file is not valid yaml: %w", err)
+			}
+			if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+				return nil, fmt.Errorf("the score file is empty")
+			}
+			issues := scoreWorkloadSchema.validate(doc.Content[0], "", make([]scoreIssue, 0))
+
+			var score map[string]interface{}
+			if err := doc.Decode(&score); err != nil {
+				issues = append(issues, scoreIssue{Line: doc.Line, Column: doc.Column, Message: err.Error()})
+			}
+			resources, _ := score["resources"].(map[string]interface{})
+			resourceNames := make([]string, 0, len(resources))
+			for k := range resources {
+				resourceNames = append(resourceNames, k)
+			}
+			issues = validateScorePlaceholders(&doc, resourceNames, "", issues)
+			slices.SortStableFunc(issues, func(a, b scoreIssue) int {
+				return a.Line - b.Line
+			})
+
+			if len(issues) > 0 {
+				lines := make([]string, len(issues))
+				for i, issue := range issues {
+					lines[i] = "line " + strconv.Itoa(issue.Line) + ": " + strings.TrimPrefix(issue.Path+": ", ": ") + issue.Message
+				}
+				return []mcp.CallToolResponseContent{
+					mcp.NewTextToolResponseContent("The Score file is invalid:\n%s", strings.Join(lines, "\n")),
+				}, nil
+			}
+
+			if image != "" {
+				containers, _ := score["containers"].(map[string]interface{})
+				for _, c := range containers {
+					if cm, ok := c.(map[string]interface{}); ok && cm["image"] == "." {
+						cm["image"] = image
+					}
+				}
+			}
+			preview := previewScoreModule(score, profile)
+			if orgId == "" {
+				return []mcp.CallToolResponseContent{
+					mcp.NewTextToolResponseContent("The Score file is valid. This is an approximate offline preview of the deployment set it produces, provide an org_id for the exact result: %s", internal.PrettyJson(preview)),
+				}, nil
+			}
+
+			hc, err := humanitec.NewHumanitecClientWithCurrentToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+			out := make([]mcp.CallToolResponseContent, 0)
+			if r, err := humanitec.CheckResponse(func() (*client.GetWorkloadProfileResponse, error) {
+				return hc.GetWorkloadProfileWithResponse(ctx, orgId, profile)
+			}).AndStatusCodeEq(http.StatusOK).RespAndError(); err != nil {
+				out = append(out, mcp.NewTextToolResponseContent("Failed to fetch workload profile '%s': %v", profile, err.Error()))
+			} else {
+				metadata, _ := score["metadata"].(map[string]interface{})
+				module, _ := preview["modules"].(map[string]interface{})[fmt.Sprint(metadata["name"])].(map[string]interface{})
+				if problems := validateAgainstProfile(module, r.JSON200.SpecSchema); len(problems) > 0 {
+					out = append(out, mcp.NewTextToolResponseContent("The workload is not compatible with workload profile '%s':\n- %s", profile, strings.Join(problems, "\n- ")))
+				}
+			}
+
+			body := client.ConvertScoreToSetJSONRequestBody{Spec: score}
+			if image != "" {
+				body.Image = &image
+			}
+			if r, err := humanitec.CheckResponse(func() (*client.ConvertScoreToSetResponse, error) {
+				return hc.ConvertScoreToSetWithResponse(ctx, orgId, body)
+			}).AndStatusCodeEq(http.StatusOK).RespAndError(); err != nil {
+				out = append(out,
+					mcp.NewTextToolResponseContent("The Humanitec API rejected the Score file: %v", err.Error()),
+					mcp.NewTextToolResponseContent("This is an approximate offline preview of the deployment set: %s", internal.PrettyJson(preview)),
+				)
+			} else {
+				out = append(out, mcp.NewTextToolResponseContent("The Score file is valid and produces the following deployment set in JSON format: %s", internal.PrettyJson(r.JSON200)))
+			}
+			return out, nil
+		},
+	}
+}
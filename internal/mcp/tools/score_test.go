@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidateScoreWorkload(t *testing.T) {
+	raw := `apiVersion: score.dev/v1b1
+metadata:
+  name: Example
+containers:
+  main:
+    command: ["run"]
+    variables:
+      DB: ${resources.db.host}
+      CACHE: ${resources.cache.host}
+resources:
+  db:
+    type: postgres
+    extra: true
+`
+	var doc yaml.Node
+	assert.NoError(t, yaml.Unmarshal([]byte(raw), &doc))
+	issues := scoreWorkloadSchema.validate(doc.Content[0], "", nil)
+	issues = validateScorePlaceholders(&doc, []string{"db"}, "", issues)
+	assert.Equal(t, []scoreIssue{
+		{Line: 3, Column: 9, Path: ".metadata.name", Message: "'Example' does not match the pattern ^[a-z0-9][a-z0-9-]{0,61}[a-z0-9]$"},
+		{Line: 6, Column: 5, Path: ".containers.main", Message: "missing required property 'image'"},
+		{Line: 13, Column: 5, Path: ".resources.db.extra", Message: "unknown property 'extra'"},
+		{Line: 9, Column: 14, Path: ".containers.main.variables.CACHE", Message: "placeholder '${resources.cache.host}' references undeclared resource 'cache'"},
+	}, issues)
+}
+
+func TestValidateScoreWorkload_openMetadataAndEscapes(t *testing.T) {
+	raw := `apiVersion: score.dev/v1b1
+metadata:
+  name: example
+  annotations:
+    team: payments
+  custom-key: anything
+containers:
+  main:
+    image: nginx
+    variables:
+      LITERAL: $${not.a.placeholder}
+      MIXED: $$${resources.cache.host}
+resources:
+  db:
+    type: postgres
+    metadata:
+      labels: {}
+`
+	var doc yaml.Node
+	assert.NoError(t, yaml.Unmarshal([]byte(raw), &doc))
+	issues := scoreWorkloadSchema.validate(doc.Content[0], "", nil)
+	issues = validateScorePlaceholders(&doc, []string{"db"}, "", issues)
+	// only the unescaped placeholder after the escaped dollar is checked
+	assert.Equal(t, []scoreIssue{
+		{Line: 12, Column: 14, Path: ".containers.main.variables.MIXED", Message: "placeholder '${resources.cache.host}' references undeclared resource 'cache'"},
+	}, issues)
+}
+
+func TestConvertScorePlaceholders(t *testing.T) {
+	assert.Equal(t,
+		"${values.host} $${resources.db.host} $$${externals.cache.host} ${metadata.name}",
+		convertScorePlaceholders("${resources.db.host} $${resources.db.host} $$${resources.cache.host} ${metadata.name}", nil, map[string]bool{"db": true}),
+	)
+}
+
+func TestPreviewScoreModule(t *testing.T) {
+	score := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "example"},
+		"containers": map[string]interface{}{
+			"main": map[string]interface{}{
+				"image":     "nginx",
+				"variables": map[string]interface{}{"DB": "${resources.db.host}", "DNS": "${resources.dns.host}", "X": "${resources.env.X}"},
+			},
+		},
+		"resources": map[string]interface{}{
+			"db":  map[string]interface{}{"type": "postgres"},
+			"dns": map[string]interface{}{"type": "dns", "id": "shared-dns"},
+			"env": map[string]interface{}{"type": "environment"},
+		},
+	}
+	preview := previewScoreModule(score, defaultWorkloadProfile)
+	module := preview["modules"].(map[string]interface{})["example"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"db": map[string]interface{}{"type": "postgres"}}, module["externals"])
+	assert.Equal(t, map[string]interface{}{"shared-dns": map[string]interface{}{"type": "dns"}}, preview["shared"])
+	container := module["spec"].(map[string]interface{})["containers"].(map[string]interface{})["main"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"DB": "${externals.db.host}", "DNS": "${shared.shared-dns.host}", "X": "${values.X}"}, container["variables"])
+}
@@ -14,18 +14,40 @@ A Humanitec organization aka org contains many applications which each container
 'resources' may be another word used for the externals and shared resources declared in the deployment set of an environment.
 When starting a new chat, always confirm the humanitec organization to work in. When checking an organisation for the first time, also check the Paths in that application.
 `,
+		Resources: renderResources,
 		Tools: []mcp.Tool{
 			NewKapaAiDocsTool(),
 			NewListPathsTool(),
 			NewCallPathTool(),
 			NewListHumanitecOrgsAndSession(),
+			NewListOrganizationMembers(),
+			NewListEnvironmentTypeDeployers(),
+			NewGrantAppRole(),
+			NewRevokeAppRole(),
 			NewListAppsAndEnvsForOrganization(),
+			NewListEnvironmentTypes(),
+			NewCreateEnvironment(),
+			NewDeleteEnvironment(),
+			NewGetOrganizationInventory(),
+			NewDetectEnvironmentDrift(),
+			NewListContainerImages(),
 			NewGetHumanitecDeploymentSets(),
+			NewGetHumanitecDeploymentStatus(),
+			NewGetAuditTimeline(),
+			NewDiffHumanitecDeploymentSets(),
+			NewGetSharedValues(),
+			NewGetWorkloadLogs(),
+			NewListActiveResources(),
+			NewListResourceDefinitions(),
+			NewExplainResourceDefinitionMatching(),
 			NewGetWorkloadProfileSchema(),
 			NewRenderCSVAsTable(),
 			NewRenderNetworkAsGraph(),
 			NewRenderTreeAsTree(),
-			NewDummyMetadataKeysTool(),
+			NewRenderTimeline(),
+			NewListOrganizationMetadataKeys(),
+			NewSearchMetadata(),
+			NewValidateScoreWorkload(),
 		},
 	}
 }
@@ -0,0 +1,395 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/humanitec/humanitec-go-autogen/client"
+
+	"github.com/humanitec/canyon-cli/internal"
+	"github.com/humanitec/canyon-cli/internal/clients/humanitec"
+	"github.com/humanitec/canyon-cli/internal/mcp"
+	"github.com/humanitec/canyon-cli/internal/ref"
+)
+
+var appRoles = []string{"viewer", "developer", "owner"}
+
+type orgMember struct {
+	Id       string            `json:"id"`
+	Name     string            `json:"name"`
+	Email    string            `json:"email,omitempty"`
+	Type     string            `json:"type"`
+	OrgRole  string            `json:"orgRole"`
+	AppRoles map[string]string `json:"appRoles,omitempty"`
+}
+
+// canManageAppRoles reports whether the roles of the current user allow managing the user roles of the app. The
+// roles map is keyed by the object the role is granted on, for example /orgs/my-org or /orgs/my-org/apps/my-app.
+func canManageAppRoles(roles map[string]string, orgId, appId string) bool {
+	switch roles["/orgs/"+orgId] {
+	case "administrator", "manager":
+		return true
+	}
+	return roles["/orgs/"+orgId+"/apps/"+appId] == "owner"
+}
+
+func listAppUserRoles(ctx context.Context, hc *humanitec.WrappedHumanitecClientImpl, orgId, appId string) ([]client.UserRoleResponse, error) {
+	r, err := humanitec.CheckResponse(func() (*client.ListUserRolesInAppResponse, error) {
+		return hc.ListUserRolesInAppWithResponse(ctx, orgId, appId)
+	}).AndStatusCodeEq(http.StatusOK).RespAndError()
+	if err != nil {
+		return nil, err
+	}
+	return *r.JSON200, nil
+}
+
+// checkCanManageAppRoles returns an error if the current user is not allowed to manage the user roles of the app.
+func checkCanManageAppRoles(ctx context.Context, hc *humanitec.WrappedHumanitecClientImpl, orgId, appId string) error {
+	r, err := humanitec.CheckResponse(func() (*client.GetCurrentUserResponse, error) {
+		return hc.GetCurrentUserWithResponse(ctx)
+	}).AndStatusCodeEq(http.StatusOK).RespAndError()
+	if err != nil {
+		return fmt.Errorf("failed to get the current user: %w", err)
+	}
+	if !canManageAppRoles(r.JSON200.Roles, orgId, appId) {
+		return fmt.Errorf("the current user must be an administrator or manager of the organization or an owner of application '%s' to manage its user roles", appId)
+	}
+	return nil
+}
+
+func NewListOrganizationMembers() mcp.Tool {
+	return mcp.Tool{
+		Name: "list_humanitec_organization_members",
+		Description: `This tool returns the users and service users of a Humanitec Organization with their Organization role and their role in each Application.
+Organization roles are 'administrator', 'manager', 'member', and 'orgViewer'. Application roles are 'viewer', 'developer', and 'owner'.
+An optional app_id regex argument can filter the Applications whose roles are returned.`,
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"org_id": map[string]interface{}{"type": "string", "description": "The Humanitec Organization (org) ID to work with."},
+				"app_id": map[string]interface{}{"type": "string", "description": "Optional regex pattern to filter for app id"},
+			},
+			"required":             []string{"org_id"},
+			"additionalProperties": false,
+		},
+		Annotations: &mcp.ToolAnnotations{Title: "List organization members", ReadOnlyHint: ref.Ref(true)},
+		Callable: func(ctx context.Context, m map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+			orgId, _ := m["org_id"].(string)
+			var appIdPattern *regexp.Regexp
+			if v, ok := m["app_id"].(string); ok {
+				var err error
+				if appIdPattern, err = regexp.CompilePOSIX(v); err != nil {
+					return nil, fmt.Errorf("invalid app_id  regex: %w", err)
+				}
+			}
+
+			hc, err := humanitec.NewHumanitecClientWithCurrentToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+			r, err := humanitec.CheckResponse(func() (*client.ListUserRolesInOrgResponse, error) {
+				return hc.ListUserRolesInOrgWithResponse(ctx, orgId)
+			}).AndStatusCodeEq(http.StatusOK).RespAndError()
+			if err != nil {
+				return nil, err
+			}
+			members := make(map[string]*orgMember, len(*r.JSON200))
+			for _, u := range *r.JSON200 {
+				members[u.Id] = &orgMember{Id: u.Id, Name: u.Name, Email: ref.Deref(u.Email, ""), Type: u.Type, OrgRole: u.Role, AppRoles: make(map[string]string)}
+			}
+
+			ar, err := humanitec.CheckResponse(func() (*client.ListApplicationsResponse, error) {
+				return hc.ListApplicationsWithResponse(ctx, orgId)
+			}).AndStatusCodeEq(http.StatusOK).RespAndError()
+			if err != nil {
+				return nil, err
+			}
+			appRolesByApp := new(sync.Map)
+			{
+				wg := new(sync.WaitGroup)
+				sem := make(chan struct{}, 10)
+				for _, app := range *ar.JSON200 {
+					if appIdPattern != nil && !appIdPattern.MatchString(app.Id) {
+						continue
+					}
+					wg.Add(1)
+					sem <- struct{}{}
+					go func() {
+						defer wg.Done()
+						defer func() { <-sem }()
+						if roles, err := listAppUserRoles(ctx, hc, orgId, app.Id); err != nil {
+							appRolesByApp.Store(app.Id, err)
+						} else {
+							appRolesByApp.Store(app.Id, roles)
+						}
+					}()
+				}
+				wg.Wait()
+			}
+
+			var appErr error
+			appRolesByApp.Range(func(key, value any) bool {
+				if e, ok := value.(error); ok {
+					appErr = errors.Join(appErr, fmt.Errorf("failed to fetch roles of app '%s': %w", key, e))
+				} else if roles, ok := value.([]client.UserRoleResponse); ok {
+					for _, u := range roles {
+						if mem, ok := members[u.Id]; ok {
+							mem.AppRoles[key.(string)] = u.Role
+						}
+					}
+				}
+				return true
+			})
+
+			out := make([]orgMember, 0, len(members))
+			for _, mem := range members {
+				out = append(out, *mem)
+			}
+			slices.SortFunc(out, func(a, b orgMember) int {
+				return strings.Compare(a.Name+a.Id, b.Name+b.Id)
+			})
+			resp := []mcp.CallToolResponseContent{
+				mcp.NewTextToolResponseContent("The Organization '%s' has the following members in JSON format: %s", orgId, internal.PrettyJson(out)),
+			}
+			if appErr != nil {
+				resp = append(resp, mcp.NewTextToolResponseContent("Some application roles could not be fetched: %v", appErr.Error()))
+			}
+			return resp, nil
+		},
+	}
+}
+
+type envTypeDeployer struct {
+	Id      string `json:"id"`
+	Name    string `json:"name"`
+	Email   string `json:"email,omitempty"`
+	Type    string `json:"type"`
+	Reason  string `json:"reason"`
+	AppRole string `json:"appRole,omitempty"`
+}
+
+func NewListEnvironmentTypeDeployers() mcp.Tool {
+	return mcp.Tool{
+		Name: "list_humanitec_environment_type_deployers",
+		Description: `This tool returns the users who can deploy to Environments of a given environment type in a Humanitec Organization.
+Organization administrators can deploy to any Environment, other users need the 'deployer' role on the environment type.
+If app_id is provided, only users who are also a 'developer' or 'owner' of that Application are returned, since deploying also requires access to the Application.`,
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"org_id":   map[string]interface{}{"type": "string", "description": "The Humanitec Organization (org) ID to work with."},
+				"env_type": map[string]interface{}{"type": "string", "description": "The environment type, for example production"},
+				"app_id":   map[string]interface{}{"type": "string", "description": "Optional Humanitec Application (app) ID to restrict the deployers to"},
+			},
+			"required":             []string{"org_id", "env_type"},
+			"additionalProperties": false,
+		},
+		Annotations: &mcp.ToolAnnotations{Title: "List environment type deployers", ReadOnlyHint: ref.Ref(true)},
+		Callable: func(ctx context.Context, m map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+			orgId, _ := m["org_id"].(string)
+			envType, _ := m["env_type"].(string)
+			appId, _ := m["app_id"].(string)
+
+			hc, err := humanitec.NewHumanitecClientWithCurrentToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+			or, err := humanitec.CheckResponse(func() (*client.ListUserRolesInOrgResponse, error) {
+				return hc.ListUserRolesInOrgWithResponse(ctx, orgId)
+			}).AndStatusCodeEq(http.StatusOK).RespAndError()
+			if err != nil {
+				return nil, err
+			}
+			er, err := humanitec.CheckResponse(func() (*client.ListUserRolesInEnvTypeResponse, error) {
+				return hc.ListUserRolesInEnvTypeWithResponse(ctx, orgId, envType)
+			}).AndStatusCodeEq(http.StatusOK).RespAndError()
+			if err != nil {
+				return nil, err
+			}
+
+			deployers := make(map[string]envTypeDeployer)
+			for _, u := range *or.JSON200 {
+				if u.Role == "administrator" {
+					deployers[u.Id] = envTypeDeployer{Id: u.Id, Name: u.Name, Email: ref.Deref(u.Email, ""), Type: u.Type, Reason: "organization administrator"}
+				}
+			}
+			for _, u := range *er.JSON200 {
+				if _, ok := deployers[u.Id]; !ok && u.Role == "deployer" {
+					deployers[u.Id] = envTypeDeployer{Id: u.Id, Name: u.Name, Email: ref.Deref(u.Email, ""), Type: u.Type, Reason: "deployer of environment type " + envType}
+				}
+			}
+
+			if appId != "" {
+				roles, err := listAppUserRoles(ctx, hc, orgId, appId)
+				if err != nil {
+					return nil, err
+				}
+				appRoleByUser := make(map[string]string, len(roles))
+				for _, u := range roles {
+					appRoleByUser[u.Id] = u.Role
+				}
+				for id, d := range deployers {
+					role := appRoleByUser[id]
+					if d.Reason != "organization administrator" && role != "developer" && role != "owner" {
+						delete(deployers, id)
+						continue
+					}
+					d.AppRole = role
+					deployers[id] = d
+				}
+			}
+
+			out := make([]envTypeDeployer, 0, len(deployers))
+			for _, d := range deployers {
+				out = append(out, d)
+			}
+			slices.SortFunc(out, func(a, b envTypeDeployer) int {
+				return strings.Compare(a.Name+a.Id, b.Name+b.Id)
+			})
+			return []mcp.CallToolResponseContent{
+				mcp.NewTextToolResponseContent("The following users can deploy to environment type '%s' in JSON format: %s", envType, internal.PrettyJson(out)),
+			}, nil
+		},
+	}
+}
+
+// grantAppRole creates or updates the role of the user in the app and returns the resulting user role, or nil if the
+// user already has the role.
+func grantAppRole(ctx context.Context, hc *humanitec.WrappedHumanitecClientImpl, orgId, appId, userId, role string) (*client.UserRoleResponse, error) {
+	if err := checkCanManageAppRoles(ctx, hc, orgId, appId); err != nil {
+		return nil, err
+	}
+	existing, err := listAppUserRoles(ctx, hc, orgId, appId)
+	if err != nil {
+		return nil, err
+	}
+
+	if i := slices.IndexFunc(existing, func(u client.UserRoleResponse) bool { return u.Id == userId }); i >= 0 {
+		if existing[i].Role == role {
+			return nil, nil
+		}
+		r, err := humanitec.CheckResponse(func() (*client.UpdateUserRoleInAppResponse, error) {
+			return hc.UpdateUserRoleInAppWithResponse(ctx, orgId, appId, userId, client.UpdateUserRoleInAppJSONRequestBody{Role: &role})
+		}).AndStatusCodeEq(http.StatusOK).RespAndError()
+		if err != nil {
+			return nil, err
+		}
+		return r.JSON200, nil
+	}
+	r, err := humanitec.CheckResponse(func() (*client.CreateUserRoleInAppResponse, error) {
+		return hc.CreateUserRoleInAppWithResponse(ctx, orgId, appId, client.CreateUserRoleInAppJSONRequestBody{Id: &userId, Role: &role})
+	}).AndStatusCodeEq(http.StatusOK, http.StatusCreated).RespAndError()
+	if err != nil {
+		return nil, err
+	} else if r.JSON200 != nil {
+		return r.JSON200, nil
+	}
+	// The client only decodes 200 responses, so the body of a 201 response is decoded here.
+	out := new(client.UserRoleResponse)
+	if err := json.Unmarshal(r.Body, out); err != nil {
+		return nil, fmt.Errorf("failed to decode the granted role: %w", err)
+	}
+	return out, nil
+}
+
+func NewGrantAppRole() mcp.Tool {
+	return mcp.Tool{
+		Name: "grant_humanitec_app_role",
+		Description: `This tool grants a user a role in a Humanitec Application, replacing any role the user already has in the Application.
+Application roles are 'viewer', 'developer', and 'owner'. The current user must be an administrator or manager of the Organization or an owner of the Application.
+This changes who can access the Application. Before calling this tool, always confirm the change with the user and then set confirm to true.`,
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"org_id":  map[string]interface{}{"type": "string", "description": "The Humanitec Organization (org) ID to work with."},
+				"app_id":  map[string]interface{}{"type": "string", "description": "The Humanitec Application (app) ID to work with."},
+				"user_id": map[string]interface{}{"type": "string", "description": "The ID of the user to grant the role to."},
+				"role":    map[string]interface{}{"type": "string", "enum": appRoles, "description": "The Application role to grant."},
+				"confirm": map[string]interface{}{"type": "boolean", "description": "Must be true to confirm that the user wants to grant the role."},
+			},
+			"required":             []string{"org_id", "app_id", "user_id", "role", "confirm"},
+			"additionalProperties": false,
+		},
+		Annotations: &mcp.ToolAnnotations{Title: "Grant application role", ReadOnlyHint: ref.Ref(false), DestructiveHint: ref.Ref(true), IdempotentHint: ref.Ref(true)},
+		Callable: func(ctx context.Context, m map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+			orgId, _ := m["org_id"].(string)
+			appId, _ := m["app_id"].(string)
+			userId, _ := m["user_id"].(string)
+			role, _ := m["role"].(string)
+			if !slices.Contains(appRoles, role) {
+				return nil, fmt.Errorf("invalid role '%s', must be one of %s", role, strings.Join(appRoles, ", "))
+			}
+			if confirm, _ := m["confirm"].(bool); !confirm {
+				return nil, fmt.Errorf("granting the role must be confirmed by the user")
+			}
+
+			hc, err := humanitec.NewHumanitecClientWithCurrentToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+			result, err := grantAppRole(ctx, hc, orgId, appId, userId, role)
+			if err != nil {
+				return nil, err
+			} else if result == nil {
+				return []mcp.CallToolResponseContent{
+					mcp.NewTextToolResponseContent("The user '%s' already has the role '%s' in Application '%s'.", userId, role, appId),
+				}, nil
+			}
+			return []mcp.CallToolResponseContent{
+				mcp.NewTextToolResponseContent("The role was granted in Application '%s': %s", appId, internal.PrettyJson(result)),
+			}, nil
+		},
+	}
+}
+
+func NewRevokeAppRole() mcp.Tool {
+	return mcp.Tool{
+		Name: "revoke_humanitec_app_role",
+		Description: `This tool removes the role of a user in a Humanitec Application so that the user no longer has access to it, unless granted through their Organization role.
+The current user must be an administrator or manager of the Organization or an owner of the Application.
+Before calling this tool, always confirm the change with the user and then set confirm to true.`,
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"org_id":  map[string]interface{}{"type": "string", "description": "The Humanitec Organization (org) ID to work with."},
+				"app_id":  map[string]interface{}{"type": "string", "description": "The Humanitec Application (app) ID to work with."},
+				"user_id": map[string]interface{}{"type": "string", "description": "The ID of the user to revoke the role of."},
+				"confirm": map[string]interface{}{"type": "boolean", "description": "Must be true to confirm that the user wants to revoke the role."},
+			},
+			"required":             []string{"org_id", "app_id", "user_id", "confirm"},
+			"additionalProperties": false,
+		},
+		Annotations: &mcp.ToolAnnotations{Title: "Revoke application role", ReadOnlyHint: ref.Ref(false), DestructiveHint: ref.Ref(true), IdempotentHint: ref.Ref(true)},
+		Callable: func(ctx context.Context, m map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+			orgId, _ := m["org_id"].(string)
+			appId, _ := m["app_id"].(string)
+			userId, _ := m["user_id"].(string)
+			if confirm, _ := m["confirm"].(bool); !confirm {
+				return nil, fmt.Errorf("revoking the role must be confirmed by the user")
+			}
+
+			hc, err := humanitec.NewHumanitecClientWithCurrentToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if err := checkCanManageAppRoles(ctx, hc, orgId, appId); err != nil {
+				return nil, err
+			}
+			if _, err := humanitec.CheckResponse(func() (*client.DeleteUserRoleInAppResponse, error) {
+				return hc.DeleteUserRoleInAppWithResponse(ctx, orgId, appId, userId)
+			}).AndStatusCodeEq(http.StatusNoContent, http.StatusOK).RespAndError(); err != nil {
+				return nil, err
+			}
+			return []mcp.CallToolResponseContent{
+				mcp.NewTextToolResponseContent("The role of user '%s' in Application '%s' was revoked.", userId, appId),
+			}, nil
+		},
+	}
+}
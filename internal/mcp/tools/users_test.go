@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/humanitec/humanitec-go-autogen/client"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/humanitec/canyon-cli/internal/clients/humanitec"
+)
+
+func TestCanManageAppRoles(t *testing.T) {
+	assert.True(t, canManageAppRoles(map[string]string{"/orgs/o": "administrator"}, "o", "a"))
+	assert.True(t, canManageAppRoles(map[string]string{"/orgs/o": "manager"}, "o", "a"))
+	assert.True(t, canManageAppRoles(map[string]string{"/orgs/o": "member", "/orgs/o/apps/a": "owner"}, "o", "a"))
+	assert.False(t, canManageAppRoles(map[string]string{"/orgs/o": "member", "/orgs/o/apps/a": "developer"}, "o", "a"))
+	assert.False(t, canManageAppRoles(map[string]string{"/orgs/o": "member", "/orgs/o/apps/b": "owner"}, "o", "a"))
+	assert.False(t, canManageAppRoles(map[string]string{"/orgs/other": "administrator"}, "o", "a"))
+}
+
+// fakeRolesClient serves the roles of a single app, replying to role creation with 201 like the API does.
+type fakeRolesClient struct {
+	client.ClientWithResponsesInterface
+	roles   []client.UserRoleResponse
+	updated string
+}
+
+func (f *fakeRolesClient) GetCurrentUserWithResponse(ctx context.Context, reqEditors ...client.RequestEditorFn) (*client.GetCurrentUserResponse, error) {
+	return &client.GetCurrentUserResponse{HTTPResponse: &http.Response{StatusCode: http.StatusOK}, JSON200: &client.UserProfileExtendedResponse{
+		Roles: client.RolesResponse{"/orgs/o": "manager"},
+	}}, nil
+}
+
+func (f *fakeRolesClient) ListUserRolesInAppWithResponse(ctx context.Context, orgId string, appId string, reqEditors ...client.RequestEditorFn) (*client.ListUserRolesInAppResponse, error) {
+	return &client.ListUserRolesInAppResponse{HTTPResponse: &http.Response{StatusCode: http.StatusOK}, JSON200: &f.roles}, nil
+}
+
+func (f *fakeRolesClient) CreateUserRoleInAppWithResponse(ctx context.Context, orgId string, appId string, body client.CreateUserRoleInAppJSONRequestBody, reqEditors ...client.RequestEditorFn) (*client.CreateUserRoleInAppResponse, error) {
+	raw, _ := json.Marshal(client.UserRoleResponse{Id: *body.Id, Role: *body.Role, Name: "New User"})
+	return &client.CreateUserRoleInAppResponse{HTTPResponse: &http.Response{StatusCode: http.StatusCreated}, Body: raw}, nil
+}
+
+func (f *fakeRolesClient) UpdateUserRoleInAppWithResponse(ctx context.Context, orgId string, appId string, userId string, body client.UpdateUserRoleInAppJSONRequestBody, reqEditors ...client.RequestEditorFn) (*client.UpdateUserRoleInAppResponse, error) {
+	f.updated = userId
+	return &client.UpdateUserRoleInAppResponse{HTTPResponse: &http.Response{StatusCode: http.StatusOK}, JSON200: &client.UserRoleResponse{Id: userId, Role: *body.Role}}, nil
+}
+
+func TestGrantAppRole(t *testing.T) {
+	fake := &fakeRolesClient{roles: []client.UserRoleResponse{{Id: "alice", Role: "developer"}}}
+	hc := &humanitec.WrappedHumanitecClientImpl{ClientWithResponsesInterface: fake}
+
+	// a new role is created with a 201 response
+	r, err := grantAppRole(context.Background(), hc, "o", "a", "bob", "viewer")
+	assert.NoError(t, err)
+	assert.Equal(t, &client.UserRoleResponse{Id: "bob", Role: "viewer", Name: "New User"}, r)
+
+	// an existing role is updated
+	r, err = grantAppRole(context.Background(), hc, "o", "a", "alice", "owner")
+	assert.NoError(t, err)
+	assert.Equal(t, &client.UserRoleResponse{Id: "alice", Role: "owner"}, r)
+	assert.Equal(t, "alice", fake.updated)
+
+	// nothing changes when the user already has the role
+	r, err = grantAppRole(context.Background(), hc, "o", "a", "alice", "developer")
+	assert.NoError(t, err)
+	assert.Nil(t, r)
+
+	_, err = grantAppRole(context.Background(), hc, "other", "a", "bob", "viewer")
+	assert.EqualError(t, err, "the current user must be an administrator or manager of the organization or an owner of application 'a' to manage its user roles")
+}
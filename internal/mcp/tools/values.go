@@ -0,0 +1,281 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/humanitec/humanitec-go-autogen/client"
+
+	"github.com/humanitec/canyon-cli/internal"
+	"github.com/humanitec/canyon-cli/internal/clients/humanitec"
+	"github.com/humanitec/canyon-cli/internal/mcp"
+	"github.com/humanitec/canyon-cli/internal/ref"
+)
+
+// sharedValue is a shared value with the value removed for secrets. Secret values must never be returned to the
+// model, only whether they are set, where they come from, and when they were last changed.
+type sharedValue struct {
+	Key         string    `json:"key"`
+	Description string    `json:"description,omitempty"`
+	Source      string    `json:"source"`
+	IsSecret    bool      `json:"isSecret"`
+	Value       *string   `json:"value,omitempty"`
+	Present     bool      `json:"present"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func newSharedValue(v client.ValueResponse) sharedValue {
+	out := sharedValue{
+		Key:         v.Key,
+		Description: v.Description,
+		Source:      string(v.Source),
+		IsSecret:    v.IsSecret,
+		Present:     v.Value != "" || v.SecretVersion != nil || v.SecretKey != nil,
+		UpdatedAt:   v.UpdatedAt,
+	}
+	if !v.IsSecret {
+		out.Value = &v.Value
+		out.Present = true
+	}
+	return out
+}
+
+type sharedValueChange struct {
+	Op       string      `json:"op"`
+	Key      string      `json:"key"`
+	Value    interface{} `json:"value,omitempty"`
+	Redacted bool        `json:"redacted,omitempty"`
+}
+
+type sharedValueVersion struct {
+	Id        string              `json:"id"`
+	CreatedAt time.Time           `json:"createdAt"`
+	CreatedBy string              `json:"createdBy"`
+	Comment   string              `json:"comment,omitempty"`
+	ResultOf  string              `json:"resultOf,omitempty"`
+	Changes   []sharedValueChange `json:"changes"`
+}
+
+// newSharedValueVersion converts a value set version into its list of changes. The full value set of the version is
+// dropped and the changed values are redacted if the key is a secret in this version or currently.
+func newSharedValueVersion(v client.ValueSetVersionResponse, currentSecrets map[string]bool) sharedValueVersion {
+	out := sharedValueVersion{
+		Id:        v.Id,
+		CreatedAt: v.CreatedAt,
+		CreatedBy: v.CreatedBy,
+		Comment:   v.Comment,
+		Changes:   make([]sharedValueChange, 0, len(v.Change)),
+	}
+	if v.ResultOf != nil {
+		out.ResultOf = string(*v.ResultOf)
+	}
+	for _, c := range v.Change {
+		parts := splitJsonPointer(c.Path)
+		key := parts[0]
+		change := sharedValueChange{Op: c.Op, Key: key}
+		if c.Value != nil {
+			secret := currentSecrets[key] || v.Values[key].IsSecret
+			if m, ok := (*c.Value).(map[string]interface{}); ok && m["is_secret"] == true {
+				secret = true
+			}
+			if secret {
+				change.Redacted = true
+			} else if m, ok := (*c.Value).(map[string]interface{}); ok && len(parts) == 1 {
+				change.Value = m["value"]
+			} else {
+				change.Value = *c.Value
+			}
+		}
+		out.Changes = append(out.Changes, change)
+	}
+	return out
+}
+
+type sharedValueDifference struct {
+	Key    string                  `json:"key"`
+	Reason string                  `json:"reason"`
+	Values map[string]*sharedValue `json:"values"`
+}
+
+// diffSharedValues compares the values of each key across environments. Secrets cannot be compared by value, so they
+// are reported as different only when they are missing in some environments or come from different sources.
+func diffSharedValues(envValues map[string][]sharedValue) []sharedValueDifference {
+	envIds := make([]string, 0, len(envValues))
+	byKey := make(map[string]map[string]*sharedValue)
+	for envId, values := range envValues {
+		envIds = append(envIds, envId)
+		for _, v := range values {
+			if byKey[v.Key] == nil {
+				byKey[v.Key] = make(map[string]*sharedValue)
+			}
+			byKey[v.Key][envId] = &v
+		}
+	}
+	slices.Sort(envIds)
+	keys := make([]string, 0, len(byKey))
+	for k := range byKey {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	out := make([]sharedValueDifference, 0)
+	for _, k := range keys {
+		values := byKey[k]
+		var reason string
+		var first *sharedValue
+		for _, envId := range envIds {
+			v := values[envId]
+			if v == nil || !v.Present {
+				reason = "missing in some environments"
+				break
+			} else if first == nil {
+				first = v
+			} else if v.IsSecret != first.IsSecret {
+				reason = "secret in some environments only"
+			} else if v.IsSecret && v.Source != first.Source && reason == "" {
+				reason = "secret set at different levels"
+			} else if !v.IsSecret && *v.Value != *first.Value {
+				reason = "different values"
+			}
+		}
+		if reason != "" {
+			d := sharedValueDifference{Key: k, Reason: reason, Values: make(map[string]*sharedValue, len(envIds))}
+			for _, envId := range envIds {
+				d.Values[envId] = values[envId]
+			}
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+func listSharedValues(ctx context.Context, hc *humanitec.WrappedHumanitecClientImpl, orgId, appId, envId, key string) ([]sharedValue, error) {
+	var values []client.ValueResponse
+	if envId == "" {
+		r, err := humanitec.CheckResponse(func() (*client.GetOrgsOrgIdAppsAppIdValuesResponse, error) {
+			return hc.GetOrgsOrgIdAppsAppIdValuesWithResponse(ctx, orgId, appId)
+		}).AndStatusCodeEq(http.StatusOK).RespAndError()
+		if err != nil {
+			return nil, err
+		}
+		values = *r.JSON200
+	} else {
+		r, err := humanitec.CheckResponse(func() (*client.GetOrgsOrgIdAppsAppIdEnvsEnvIdValuesResponse, error) {
+			return hc.GetOrgsOrgIdAppsAppIdEnvsEnvIdValuesWithResponse(ctx, orgId, appId, envId)
+		}).AndStatusCodeEq(http.StatusOK).RespAndError()
+		if err != nil {
+			return nil, err
+		}
+		values = *r.JSON200
+	}
+	out := make([]sharedValue, 0, len(values))
+	for _, v := range values {
+		if key == "" || v.Key == key {
+			out = append(out, newSharedValue(v))
+		}
+	}
+	slices.SortFunc(out, func(a, b sharedValue) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return out, nil
+}
+
+func NewGetSharedValues() mcp.Tool {
+	return mcp.Tool{
+		Name: "get_humanitec_shared_values",
+		Description: `This tool returns the shared values of a Humanitec Application or of its Environments. Environment values include the values inherited from the Application, the source field indicates whether a value is set on the "app" or the "env".
+When multiple env_ids are provided, the values are also compared across the Environments and the keys that differ are reported.
+With include_history, the change history of each Environment is returned, optionally limited to the changes of a single key.
+Secret values are always redacted: only whether they are present, their source, and when they were last updated are returned.`,
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"org_id":          map[string]interface{}{"type": "string", "description": "The Humanitec Organization (org) ID to work with."},
+				"app_id":          map[string]interface{}{"type": "string", "description": "The Humanitec Application (app) ID to work with."},
+				"env_ids":         map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Optional Environment IDs, if empty the Application level values are returned."},
+				"key":             map[string]interface{}{"type": "string", "description": "Optional key of a single shared value to return, for example DATABASE_URL"},
+				"include_history": map[string]interface{}{"type": "boolean", "description": "Whether to return the change history of the values in each Environment"},
+			},
+			"required":             []string{"org_id", "app_id"},
+			"additionalProperties": false,
+		},
+		Annotations: &mcp.ToolAnnotations{Title: "Get shared values", ReadOnlyHint: ref.Ref(true)},
+		Callable: func(ctx context.Context, m map[string]interface{}) ([]mcp.CallToolResponseContent, error) {
+			orgId, _ := m["org_id"].(string)
+			appId, _ := m["app_id"].(string)
+			key, _ := m["key"].(string)
+			includeHistory, _ := m["include_history"].(bool)
+			envIds := make([]string, 0)
+			if raw, ok := m["env_ids"].([]interface{}); ok {
+				for _, e := range raw {
+					if s, ok := e.(string); ok && s != "" {
+						envIds = append(envIds, s)
+					}
+				}
+			}
+
+			hc, err := humanitec.NewHumanitecClientWithCurrentToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if len(envIds) == 0 {
+				values, err := listSharedValues(ctx, hc, orgId, appId, "", key)
+				if err != nil {
+					return nil, err
+				}
+				return []mcp.CallToolResponseContent{
+					mcp.NewTextToolResponseContent("The Application '%s' has the following shared values in JSON format: %s", appId, internal.PrettyJson(values)),
+				}, nil
+			}
+
+			out := make([]mcp.CallToolResponseContent, 0)
+			envValues := make(map[string][]sharedValue, len(envIds))
+			for _, envId := range envIds {
+				values, err := listSharedValues(ctx, hc, orgId, appId, envId, key)
+				if err != nil {
+					return nil, fmt.Errorf("failed to list the values of environment '%s': %w", envId, err)
+				}
+				envValues[envId] = values
+			}
+			out = append(out, mcp.NewTextToolResponseContent("The shared values of each Environment in JSON format: %s", internal.PrettyJson(envValues)))
+			if len(envIds) > 1 {
+				out = append(out, mcp.NewTextToolResponseContent("The shared values that differ between the Environments in JSON format: %s", internal.PrettyJson(diffSharedValues(envValues))))
+			}
+
+			if includeHistory {
+				history := make(map[string][]sharedValueVersion, len(envIds))
+				for _, envId := range envIds {
+					params := &client.GetOrgsOrgIdAppsAppIdEnvsEnvIdValueSetVersionsParams{}
+					if key != "" {
+						params.KeyChanged = &key
+					}
+					r, err := humanitec.CheckResponse(func() (*client.GetOrgsOrgIdAppsAppIdEnvsEnvIdValueSetVersionsResponse, error) {
+						return hc.GetOrgsOrgIdAppsAppIdEnvsEnvIdValueSetVersionsWithResponse(ctx, orgId, appId, envId, params)
+					}).AndStatusCodeEq(http.StatusOK).RespAndError()
+					if err != nil {
+						out = append(out, mcp.NewTextToolResponseContent("Failed to fetch the value history of environment '%s': %v", envId, err.Error()))
+						continue
+					}
+					secrets := make(map[string]bool)
+					for _, v := range envValues[envId] {
+						secrets[v.Key] = v.IsSecret
+					}
+					versions := make([]sharedValueVersion, 0, len(*r.JSON200))
+					for _, v := range *r.JSON200 {
+						versions = append(versions, newSharedValueVersion(v, secrets))
+					}
+					slices.SortFunc(versions, func(a, b sharedValueVersion) int {
+						return b.CreatedAt.Compare(a.CreatedAt)
+					})
+					history[envId] = versions
+				}
+				out = append(out, mcp.NewTextToolResponseContent("The change history of the shared values, newest first, in JSON format: %s", internal.PrettyJson(history)))
+			}
+			return out, nil
+		},
+	}
+}
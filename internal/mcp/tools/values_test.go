@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/humanitec/humanitec-go-autogen/client"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/humanitec/canyon-cli/internal/ref"
+)
+
+func TestSharedValueRedaction(t *testing.T) {
+	secret := newSharedValue(client.ValueResponse{Key: "TOKEN", IsSecret: true, Value: "", SecretVersion: ref.Ref("1"), Source: "env"})
+	assert.Nil(t, secret.Value)
+	assert.True(t, secret.Present)
+
+	plain := newSharedValue(client.ValueResponse{Key: "LOG_LEVEL", Value: "info", Source: "app"})
+	assert.Equal(t, "info", *plain.Value)
+
+	var secretPatch interface{} = map[string]interface{}{"value": "hunter2", "is_secret": true}
+	var plainPatch interface{} = "debug"
+	version := newSharedValueVersion(client.ValueSetVersionResponse{
+		Id: "v1",
+		Change: client.JSONPatchesResponse{
+			{Op: "add", Path: "/TOKEN", Value: &secretPatch},
+			{Op: "replace", Path: "/PASSWORD/value", Value: &plainPatch},
+			{Op: "replace", Path: "/LOG_LEVEL/value", Value: &plainPatch},
+			{Op: "remove", Path: "/OLD"},
+		},
+		Values: client.ValueSetResponse{"PASSWORD": {IsSecret: true}},
+	}, map[string]bool{})
+	assert.Equal(t, []sharedValueChange{
+		{Op: "add", Key: "TOKEN", Redacted: true},
+		{Op: "replace", Key: "PASSWORD", Redacted: true},
+		{Op: "replace", Key: "LOG_LEVEL", Value: "debug"},
+		{Op: "remove", Key: "OLD"},
+	}, version.Changes)
+}
+
+func TestDiffSharedValues(t *testing.T) {
+	diffs := diffSharedValues(map[string][]sharedValue{
+		"development": {
+			{Key: "LOG_LEVEL", Value: ref.Ref("debug"), Present: true},
+			{Key: "REGION", Value: ref.Ref("eu"), Present: true},
+			{Key: "TOKEN", IsSecret: true, Source: "app", Present: true},
+		},
+		"production": {
+			{Key: "LOG_LEVEL", Value: ref.Ref("info"), Present: true},
+			{Key: "REGION", Value: ref.Ref("eu"), Present: true},
+			{Key: "TOKEN", IsSecret: true, Source: "env", Present: true},
+			{Key: "EXTRA", Value: ref.Ref("x"), Present: true},
+		},
+	})
+	reasons := make(map[string]string)
+	for _, d := range diffs {
+		reasons[d.Key] = d.Reason
+	}
+	assert.Equal(t, map[string]string{
+		"EXTRA":     "missing in some environments",
+		"LOG_LEVEL": "different values",
+		"TOKEN":     "secret set at different levels",
+	}, reasons)
+}
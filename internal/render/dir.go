@@ -0,0 +1,33 @@
+package render
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// defaultLocalDir returns the per-user directory the local store writes renders to when none is configured. Renders
+// contain details of the organization, so they are not written to a directory shared with other users.
+func defaultLocalDir() string {
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "canyon", "renders")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("canyon-renders-%d", os.Getuid()))
+}
+
+// ensurePrivateDir creates the render directory readable only by the current user, and refuses an existing directory
+// that is owned by another user since they could read or replace the renders written to it.
+func ensurePrivateDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create render directory: %w", err)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to check render directory: %w", err)
+	} else if !info.IsDir() {
+		return fmt.Errorf("render directory %s is not a directory", dir)
+	} else if !ownedByCurrentUser(info) {
+		return fmt.Errorf("render directory %s is owned by another user", dir)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+//go:build unix
+
+package render
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStore_private(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "renders")
+	_, err := NewLocalStore(dir).Put(context.Background(), "a.html", "text/html", []byte("<p>hi</p>"))
+	assert.NoError(t, err)
+	info, err := os.Stat(dir)
+	if assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+	}
+	info, err = os.Stat(filepath.Join(dir, "a.html"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	}
+}
+
+func TestEnsurePrivateDir_other_owner(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("changing the owner of the directory requires root")
+	}
+	dir := t.TempDir()
+	assert.NoError(t, os.Chown(dir, 65534, 65534))
+	assert.EqualError(t, ensurePrivateDir(dir), "render directory "+dir+" is owned by another user")
+	_, err := NewLocalStore(dir).Put(context.Background(), "a.html", "text/html", []byte("<p>hi</p>"))
+	assert.Error(t, err)
+}
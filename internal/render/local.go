@@ -0,0 +1,46 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// LocalStore writes renders to a directory and returns file:// URLs, so rendering works without an object store.
+type LocalStore struct {
+	Dir string
+}
+
+func NewLocalStore(dir string) *LocalStore {
+	return &LocalStore{Dir: dir}
+}
+
+func (s *LocalStore) Put(ctx context.Context, name string, contentType string, content []byte) (string, error) {
+	if err := ensurePrivateDir(s.Dir); err != nil {
+		return "", err
+	}
+	p := filepath.Join(s.Dir, filepath.Base(name))
+	if err := os.WriteFile(p, content, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write render: %w", err)
+	}
+	return fileURL(p)
+}
+
+func (s *LocalStore) Find(ctx context.Context, prefix string) (string, bool, error) {
+	matches, err := filepath.Glob(filepath.Join(s.Dir, filepath.Base(prefix)) + "*")
+	if err != nil || len(matches) == 0 {
+		return "", false, err
+	}
+	u, err := fileURL(matches[0])
+	return u, err == nil, err
+}
+
+func fileURL(p string) (string, error) {
+	p, err := filepath.Abs(p)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve render path: %w", err)
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(p)}).String(), nil
+}
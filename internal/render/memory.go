@@ -0,0 +1,49 @@
+package render
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type MemoryObject struct {
+	ContentType string
+	Content     []byte
+}
+
+// MemoryStore keeps renders in memory. It is intended for tests.
+type MemoryStore struct {
+	lock    sync.RWMutex
+	objects map[string]MemoryObject
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{objects: make(map[string]MemoryObject)}
+}
+
+func (s *MemoryStore) Put(ctx context.Context, name string, contentType string, content []byte) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.objects[name] = MemoryObject{ContentType: contentType, Content: slices.Clone(content)}
+	return "memory://" + name, nil
+}
+
+// Get returns the render stored under the name.
+func (s *MemoryStore) Get(name string) (MemoryObject, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	o, ok := s.objects[name]
+	return o, ok
+}
+
+func (s *MemoryStore) Find(ctx context.Context, prefix string) (string, bool, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	for name := range s.objects {
+		if strings.HasPrefix(name, prefix) {
+			return "memory://" + name, true, nil
+		}
+	}
+	return "", false, nil
+}
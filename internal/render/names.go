@@ -0,0 +1,85 @@
+package render
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Finder is implemented by stores that can look up a stored render by the prefix of its name. This allows identical
+// renders to be stored once and shared.
+type Finder interface {
+	Find(ctx context.Context, prefix string) (string, bool, error)
+}
+
+var invalidSlugChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// normalizeSlug lowercases the slug and replaces anything but letters, digits, and dashes with a dash.
+func normalizeSlug(slug string) (string, error) {
+	out := strings.Trim(invalidSlugChars.ReplaceAllString(strings.ToLower(slug), "-"), "-")
+	if out == "" || len(out) > 64 {
+		return "", fmt.Errorf("invalid slug '%s': must contain 1 to 64 letters, digits, or dashes", slug)
+	}
+	return out, nil
+}
+
+// contentPrefix is the content address of the render, the first 32 hex characters of the sha256 of the content.
+func contentPrefix(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:16])
+}
+
+func randomSuffix() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random name: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// StableName is the name that identifies the render independent of the store: the normalized slug if one is given,
+// otherwise the content address. Unlike the stored names it has no random suffix.
+func StableName(ext string, content []byte, slug string) (string, error) {
+	if slug != "" {
+		normalized, err := normalizeSlug(slug)
+		if err != nil {
+			return "", err
+		}
+		return normalized + ext, nil
+	}
+	return contentPrefix(content) + ext, nil
+}
+
+// PutContent stores the render under a content addressed name like <sha256 prefix>-<random suffix>.<ext> and
+// returns its URL. If the store can find a previous render with the same content, that render is returned instead of
+// storing a duplicate. The random suffix keeps the names unguessable even if the content is known.
+//
+// With a slug the render is instead stored under <slug>.<ext>, replacing any previous render with the same slug so
+// that it can be updated in place.
+func PutContent(ctx context.Context, store RenderStore, contentType string, ext string, content []byte, slug string) (string, error) {
+	if slug != "" {
+		name, err := StableName(ext, content, slug)
+		if err != nil {
+			return "", err
+		}
+		return store.Put(ctx, name, contentType, content)
+	}
+
+	prefix := contentPrefix(content)
+	if f, ok := store.(Finder); ok {
+		if u, found, err := f.Find(ctx, prefix+"-"); err != nil {
+			return "", fmt.Errorf("failed to look up existing render: %w", err)
+		} else if found {
+			return u, nil
+		}
+	}
+	suffix, err := randomSuffix()
+	if err != nil {
+		return "", err
+	}
+	return store.Put(ctx, prefix+"-"+suffix+ext, contentType, content)
+}
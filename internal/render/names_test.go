@@ -0,0 +1,74 @@
+package render
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPutContent_dedupes_identical_content(t *testing.T) {
+	s := NewMemoryStore()
+	u1, err := PutContent(context.Background(), s, "text/html", ".html", []byte("<p>hi</p>"), "")
+	assert.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^memory://[0-9a-f]{32}-[0-9a-f]{16}\.html$`), u1)
+	u2, err := PutContent(context.Background(), s, "text/html", ".html", []byte("<p>hi</p>"), "")
+	assert.NoError(t, err)
+	assert.Equal(t, u1, u2)
+
+	u3, err := PutContent(context.Background(), s, "text/html", ".html", []byte("<p>bye</p>"), "")
+	assert.NoError(t, err)
+	assert.NotEqual(t, u1, u3)
+	assert.NotEqual(t, u1[:len("memory://")+32], u3[:len("memory://")+32])
+}
+
+func TestPutContent_slug_updates_in_place(t *testing.T) {
+	s := NewMemoryStore()
+	u1, err := PutContent(context.Background(), s, "text/html", ".html", []byte("<p>v1</p>"), "Team Overview")
+	assert.NoError(t, err)
+	assert.Equal(t, "memory://team-overview.html", u1)
+	u2, err := PutContent(context.Background(), s, "text/html", ".html", []byte("<p>v2</p>"), "team-overview")
+	assert.NoError(t, err)
+	assert.Equal(t, u1, u2)
+	o, _ := s.Get("team-overview.html")
+	assert.Equal(t, "<p>v2</p>", string(o.Content))
+}
+
+func TestPutContent_invalid_slug(t *testing.T) {
+	_, err := PutContent(context.Background(), NewMemoryStore(), "text/html", ".html", []byte("x"), "!!!")
+	assert.EqualError(t, err, "invalid slug '!!!': must contain 1 to 64 letters, digits, or dashes")
+	_, err = PutContent(context.Background(), NewMemoryStore(), "text/html", ".html", []byte("x"), strings.Repeat("a", 65))
+	assert.Error(t, err)
+}
+
+func TestPutContent_local_store(t *testing.T) {
+	s := NewLocalStore(filepath.Join(t.TempDir(), "renders"))
+	u1, err := PutContent(context.Background(), s, "text/html", ".html", []byte("<p>hi</p>"), "")
+	assert.NoError(t, err)
+	u2, err := PutContent(context.Background(), s, "text/html", ".html", []byte("<p>hi</p>"), "")
+	assert.NoError(t, err)
+	assert.Equal(t, u1, u2)
+}
+
+func TestPutContent_server_store_slug(t *testing.T) {
+	s, err := NewServerStore("127.0.0.1:0", "", false)
+	assert.NoError(t, err)
+	u1, err := PutContent(context.Background(), s, "text/html", ".html", []byte("<p>v1</p>"), "graph")
+	assert.NoError(t, err)
+	u2, err := PutContent(context.Background(), s, "text/html", ".html", []byte("<p>v2</p>"), "graph")
+	assert.NoError(t, err)
+	assert.Equal(t, u1, u2)
+	assert.True(t, strings.HasSuffix(u1, "/graph.html"))
+}
+
+func TestStableName(t *testing.T) {
+	n, err := StableName(".html", []byte("<p>hi</p>"), "")
+	assert.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{32}\.html$`), n)
+	n, err = StableName(".html", []byte("<p>hi</p>"), "My Graph")
+	assert.NoError(t, err)
+	assert.Equal(t, "my-graph.html", n)
+}
@@ -0,0 +1,110 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"github.com/humanitec/canyon-cli/internal/config"
+)
+
+const (
+	defaultPresignTtl = 24 * time.Hour
+	maxPresignTtl     = 7 * 24 * time.Hour
+)
+
+// S3Store uploads renders to an S3 compatible bucket, such as Minio. The bucket is assumed to be private, so a
+// presigned URL that expires after the UrlTtl is returned. Only publicly readable buckets return the object URL.
+type S3Store struct {
+	Client      *minio.Client
+	Bucket      string
+	Endpoint    *url.URL
+	Public      bool
+	UrlTtl      time.Duration
+	ExpireAfter time.Duration
+}
+
+func NewS3Store(c config.RenderS3) (*S3Store, error) {
+	if c.Endpoint == "" || c.AccessKeyId == "" || c.SecretAccessKey == "" || c.Bucket == "" {
+		return nil, fmt.Errorf("missing required s3 render store settings (endpoint, access key id, secret access key, bucket) in the config file or MINIO_* env variables")
+	}
+	endpoint, err := url.Parse(c.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint '%s', expected a url like https://host:port", c.Endpoint)
+	}
+	useSSL := endpoint.Scheme != "http"
+	if c.UseSSL != nil {
+		useSSL = *c.UseSSL
+	}
+	client, err := minio.New(endpoint.Host, &minio.Options{
+		Creds:  credentials.NewStaticV4(c.AccessKeyId, c.SecretAccessKey, ""),
+		Secure: useSSL,
+		Region: c.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create s3 client: %w", err)
+	}
+	store := &S3Store{Client: client, Bucket: c.Bucket, Endpoint: endpoint, Public: c.Public, UrlTtl: defaultPresignTtl}
+	if c.UrlTtl != "" {
+		if store.UrlTtl, err = time.ParseDuration(c.UrlTtl); err != nil {
+			return nil, fmt.Errorf("invalid s3 url ttl: %w", err)
+		} else if store.UrlTtl < time.Second || store.UrlTtl > maxPresignTtl {
+			return nil, fmt.Errorf("invalid s3 url ttl '%s': must be between 1s and %s", c.UrlTtl, maxPresignTtl)
+		}
+	}
+	if c.ExpireAfter != "" {
+		if store.ExpireAfter, err = time.ParseDuration(c.ExpireAfter); err != nil || store.ExpireAfter <= 0 {
+			return nil, fmt.Errorf("invalid s3 expire after '%s': must be a positive duration", c.ExpireAfter)
+		}
+	}
+	return store, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, name string, contentType string, content []byte) (string, error) {
+	opts := minio.PutObjectOptions{ContentType: contentType}
+	if s.ExpireAfter > 0 {
+		expires := time.Now().Add(s.ExpireAfter).UTC()
+		opts.Expires = expires
+		opts.UserTags = map[string]string{"canyon-render": "true", "canyon-expires": expires.Format("2006-01-02")}
+	}
+	info, err := s.Client.PutObject(ctx, s.Bucket, name, bytes.NewReader(content), int64(len(content)), opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload object to s3: %w", err)
+	}
+	slog.Info("Successfully uploaded render", slog.String("bucket", s.Bucket), slog.String("object", name), slog.Int64("size", info.Size))
+	return s.objectURL(ctx, name)
+}
+
+func (s *S3Store) Find(ctx context.Context, prefix string) (string, bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for o := range s.Client.ListObjects(ctx, s.Bucket, minio.ListObjectsOptions{Prefix: prefix, MaxKeys: 1}) {
+		if o.Err != nil {
+			return "", false, o.Err
+		}
+		u, err := s.objectURL(ctx, o.Key)
+		return u, err == nil, err
+	}
+	return "", false, nil
+}
+
+// objectURL returns a presigned URL for the object, or the plain object URL if the bucket is public.
+func (s *S3Store) objectURL(ctx context.Context, name string) (string, error) {
+	if !s.Public {
+		u, err := s.Client.PresignedGetObject(ctx, s.Bucket, name, s.UrlTtl, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to presign render url: %w", err)
+		}
+		return u.String(), nil
+	}
+	u := *s.Endpoint
+	u.Path = path.Join("/", u.Path, s.Bucket, name)
+	return u.String(), nil
+}
@@ -0,0 +1,84 @@
+package render
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/humanitec/canyon-cli/internal/config"
+)
+
+// fakeS3 is a minimal S3 compatible server that accepts object uploads and bucket location requests.
+type fakeS3 struct {
+	lock    sync.Mutex
+	headers map[string]http.Header
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	switch {
+	case r.Method == http.MethodGet && r.URL.Query().Has("location"):
+		_, _ = io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?><LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/">us-east-1</LocationConstraint>`)
+	case r.Method == http.MethodPut:
+		raw, _ := io.ReadAll(r.Body)
+		f.headers[r.URL.Path] = r.Header.Clone()
+		f.objects[r.URL.Path] = raw
+		w.Header().Set("ETag", `"d41d8cd98f00b204e9800998ecf8427e"`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newFakeS3Store(t *testing.T, c config.RenderS3) (*S3Store, *fakeS3) {
+	fake := &fakeS3{headers: make(map[string]http.Header), objects: make(map[string][]byte)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	c.Endpoint, c.AccessKeyId, c.SecretAccessKey, c.Bucket = server.URL, "key", "secret", "renders"
+	s, err := NewS3Store(c)
+	assert.NoError(t, err)
+	return s, fake
+}
+
+func TestS3Store_presigned(t *testing.T) {
+	s, fake := newFakeS3Store(t, config.RenderS3{UrlTtl: "1h", ExpireAfter: "720h"})
+	u, err := s.Put(context.Background(), "graph.html", "text/html", []byte("<p>hi</p>"))
+	assert.NoError(t, err)
+
+	// The payload may be sent with aws-chunked signed encoding over plain http.
+	assert.Contains(t, string(fake.objects["/renders/graph.html"]), "<p>hi</p>")
+	h := fake.headers["/renders/graph.html"]
+	assert.Equal(t, "text/html", h.Get("Content-Type"))
+	expires, err := http.ParseTime(h.Get("Expires"))
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(720*time.Hour), expires, time.Minute)
+	tags, _ := url.ParseQuery(h.Get("X-Amz-Tagging"))
+	assert.Equal(t, "true", tags.Get("canyon-render"))
+
+	parsed, err := url.Parse(u)
+	assert.NoError(t, err)
+	assert.Equal(t, "/renders/graph.html", parsed.Path)
+	assert.Equal(t, "3600", parsed.Query().Get("X-Amz-Expires"))
+	assert.NotEmpty(t, parsed.Query().Get("X-Amz-Signature"))
+}
+
+func TestS3Store_public(t *testing.T) {
+	s, fake := newFakeS3Store(t, config.RenderS3{Public: true})
+	u, err := s.Put(context.Background(), "graph.html", "text/html", []byte("<p>hi</p>"))
+	assert.NoError(t, err)
+	assert.Equal(t, s.Endpoint.String()+"/renders/graph.html", u)
+	assert.Empty(t, fake.headers["/renders/graph.html"].Get("X-Amz-Tagging"))
+}
+
+func TestNewS3Store_invalidTtl(t *testing.T) {
+	_, err := NewS3Store(config.RenderS3{Endpoint: "http://localhost:9000", AccessKeyId: "key", SecretAccessKey: "secret", Bucket: "renders", UrlTtl: "240h"})
+	assert.EqualError(t, err, "invalid s3 url ttl '240h': must be between 1s and 168h0m0s")
+}
@@ -0,0 +1,185 @@
+package render
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxRenders is the number of renders a server store keeps before evicting the oldest, the same as the number
+// of renders registered as MCP resources.
+const DefaultMaxRenders = 100
+
+// ServerStore keeps renders in memory, or on disk when a directory is set, and serves them from an embedded HTTP
+// server on localhost. Each render is served under a random unguessable path so other local users cannot enumerate
+// them. Only the most recently stored MaxRenders are kept, and the files of evicted renders are removed, so that a long
+// session does not grow without bound.
+type ServerStore struct {
+	Dir        string
+	MaxRenders int
+
+	listener net.Listener
+	baseURL  *url.URL
+	lock     sync.RWMutex
+	objects  map[string]MemoryObject
+	paths    map[string]string
+	// names are the names of the stored renders, least recently stored first.
+	names []string
+}
+
+// isLoopbackAddr returns whether the host of the address only accepts connections from the local machine.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	} else if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// NewServerStore listens on the address, for example 127.0.0.1:0 to pick a random free port. The renders are served
+// without authentication, so addresses that are reachable from the network are rejected unless allowRemote is set.
+// The server does not handle requests until Serve is called.
+func NewServerStore(addr string, dir string, allowRemote bool) (*ServerStore, error) {
+	if !allowRemote && !isLoopbackAddr(addr) {
+		return nil, fmt.Errorf("refusing to serve renders on the non-loopback address %s without authentication, use a loopback address such as 127.0.0.1:0 or explicitly allow remote access", addr)
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	return &ServerStore{
+		Dir:        dir,
+		MaxRenders: DefaultMaxRenders,
+		listener:   l,
+		baseURL:    &url.URL{Scheme: "http", Host: l.Addr().String()},
+		objects:    make(map[string]MemoryObject),
+		paths:      make(map[string]string),
+	}, nil
+}
+
+// URL returns the base URL of the server.
+func (s *ServerStore) URL() string {
+	return s.baseURL.String()
+}
+
+// Serve handles requests until the context is cancelled.
+func (s *ServerStore) Serve(ctx context.Context) error {
+	server := &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	slog.Info("Serving renders", slog.String("url", s.URL()))
+	if err := server.Serve(s.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Put stores the render under a new random path, or the existing path if a render with the same name was stored
+// before so that it is updated in place.
+func (s *ServerStore) Put(ctx context.Context, name string, contentType string, content []byte) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	p, ok := s.paths[name]
+	if !ok {
+		token := make([]byte, 16)
+		if _, err := rand.Read(token); err != nil {
+			return "", fmt.Errorf("failed to generate render path: %w", err)
+		}
+		p = "/" + hex.EncodeToString(token) + "/" + path.Base(name)
+	}
+
+	o := MemoryObject{ContentType: contentType, Content: content}
+	if s.Dir != "" {
+		if err := ensurePrivateDir(s.Dir); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(s.diskPath(p), content, 0o600); err != nil {
+			return "", fmt.Errorf("failed to write render: %w", err)
+		}
+		o.Content = nil
+	}
+	s.objects[p] = o
+	s.paths[name] = p
+	s.names = append(slices.DeleteFunc(s.names, func(n string) bool { return n == name }), name)
+	for s.MaxRenders > 0 && len(s.names) > s.MaxRenders {
+		evicted := s.paths[s.names[0]]
+		if s.Dir != "" {
+			if err := os.Remove(s.diskPath(evicted)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				slog.Warn("failed to remove evicted render", slog.String("name", s.names[0]), slog.Any("err", err))
+			}
+		}
+		delete(s.objects, evicted)
+		delete(s.paths, s.names[0])
+		s.names = s.names[1:]
+	}
+	return s.pathURL(p), nil
+}
+
+func (s *ServerStore) Find(ctx context.Context, prefix string) (string, bool, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	for name, p := range s.paths {
+		if strings.HasPrefix(name, prefix) {
+			return s.pathURL(p), true, nil
+		}
+	}
+	return "", false, nil
+}
+
+func (s *ServerStore) pathURL(p string) string {
+	u := *s.baseURL
+	u.Path = p
+	return u.String()
+}
+
+func (s *ServerStore) diskPath(p string) string {
+	return filepath.Join(s.Dir, filepath.Base(path.Dir(p))+"-"+path.Base(p))
+}
+
+func (s *ServerStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	s.lock.RLock()
+	o, ok := s.objects[r.URL.Path]
+	s.lock.RUnlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	content := o.Content
+	if s.Dir != "" {
+		var err error
+		if content, err = os.ReadFile(s.diskPath(r.URL.Path)); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", o.ContentType)
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	_, _ = w.Write(content)
+}
@@ -0,0 +1,113 @@
+package render
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerStore(t *testing.T) {
+	for name, dir := range map[string]string{"memory": "", "disk": t.TempDir()} {
+		t.Run(name, func(t *testing.T) {
+			s, err := NewServerStore("127.0.0.1:0", dir, false)
+			assert.NoError(t, err)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() { _ = s.Serve(ctx) }()
+
+			u, err := s.Put(ctx, "graph.html", "text/html", []byte("<p>hi</p>"))
+			assert.NoError(t, err)
+			assert.True(t, strings.HasPrefix(u, s.URL()+"/"))
+			assert.True(t, strings.HasSuffix(u, "/graph.html"))
+
+			resp, err := http.Get(u)
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+			raw, _ := io.ReadAll(resp.Body)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "text/html", resp.Header.Get("Content-Type"))
+			assert.Equal(t, "<p>hi</p>", string(raw))
+
+			resp2, err := http.Get(s.URL() + "/graph.html")
+			assert.NoError(t, err)
+			defer resp2.Body.Close()
+			assert.Equal(t, http.StatusNotFound, resp2.StatusCode)
+		})
+	}
+}
+
+func TestNewServerStore_loopback(t *testing.T) {
+	for addr, ok := range map[string]bool{
+		"127.0.0.1:0": true,
+		"[::1]:0":     true,
+		"localhost:0": true,
+		":0":          false,
+		"0.0.0.0:0":   false,
+		"10.0.0.1:0":  false,
+		"example.com": false,
+	} {
+		assert.Equal(t, ok, isLoopbackAddr(addr), addr)
+	}
+
+	_, err := NewServerStore("0.0.0.0:0", "", false)
+	assert.EqualError(t, err, "refusing to serve renders on the non-loopback address 0.0.0.0:0 without authentication, use a loopback address such as 127.0.0.1:0 or explicitly allow remote access")
+	s, err := NewServerStore("0.0.0.0:0", "", true)
+	if assert.NoError(t, err) {
+		assert.NoError(t, s.listener.Close())
+	}
+}
+
+func TestServerStore_memory_eviction(t *testing.T) {
+	s, err := NewServerStore("127.0.0.1:0", "", false)
+	assert.NoError(t, err)
+	defer s.listener.Close()
+	s.MaxRenders = 2
+
+	ctx := context.Background()
+	u1, _ := s.Put(ctx, "a.html", "text/html", []byte("a"))
+	_, _ = s.Put(ctx, "b.html", "text/html", []byte("b"))
+	// storing a again makes b the oldest render
+	u1b, _ := s.Put(ctx, "a.html", "text/html", []byte("a2"))
+	assert.Equal(t, u1, u1b)
+	_, _ = s.Put(ctx, "c.html", "text/html", []byte("c"))
+
+	_, found, _ := s.Find(ctx, "b")
+	assert.False(t, found)
+	for _, prefix := range []string{"a", "c"} {
+		_, found, _ = s.Find(ctx, prefix)
+		assert.True(t, found, prefix)
+	}
+	assert.Len(t, s.objects, 2)
+	assert.Len(t, s.paths, 2)
+}
+
+func TestServerStore_disk_eviction(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewServerStore("127.0.0.1:0", dir, false)
+	assert.NoError(t, err)
+	defer s.listener.Close()
+	s.MaxRenders = 2
+
+	ctx := context.Background()
+	for _, name := range []string{"a.html", "b.html", "c.html"} {
+		_, err := s.Put(ctx, name, "text/html", []byte(name))
+		assert.NoError(t, err)
+	}
+
+	_, found, _ := s.Find(ctx, "a")
+	assert.False(t, found)
+	assert.Len(t, s.names, 2)
+	assert.Len(t, s.objects, 2)
+	assert.Len(t, s.paths, 2)
+	files, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+	for _, f := range files {
+		assert.NotContains(t, f.Name(), "a.html")
+	}
+}
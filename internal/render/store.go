@@ -0,0 +1,72 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/humanitec/canyon-cli/internal/config"
+)
+
+const (
+	StoreLocal  = "local"
+	StoreS3     = "s3"
+	StoreMemory = "memory"
+)
+
+// RenderStore stores rendered artifacts and returns a URL the user can open to view them.
+type RenderStore interface {
+	Put(ctx context.Context, name string, contentType string, content []byte) (string, error)
+}
+
+// NewFromConfig returns the render store selected by the config. Without an explicit store, the S3 store is used when
+// the MINIO_* env variables are set, for backwards compatibility, and the local store otherwise.
+func NewFromConfig(c config.Render) (RenderStore, error) {
+	s3c := s3ConfigWithEnv(c.S3)
+	store := c.Store
+	if store == "" {
+		store = StoreLocal
+		if s3c.Endpoint != "" && s3c.Bucket != "" {
+			store = StoreS3
+		}
+	}
+	switch store {
+	case StoreLocal:
+		dir := c.Local.Dir
+		if dir == "" {
+			dir = defaultLocalDir()
+		}
+		return NewLocalStore(dir), nil
+	case StoreS3:
+		return NewS3Store(s3c)
+	case StoreMemory:
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown render store '%s', expected one of %s, %s, %s", store, StoreLocal, StoreS3, StoreMemory)
+	}
+}
+
+func s3ConfigWithEnv(c config.RenderS3) config.RenderS3 {
+	for v, env := range map[*string]string{
+		&c.Endpoint:        "MINIO_ENDPOINT",
+		&c.AccessKeyId:     "MINIO_ACCESS_KEY_ID",
+		&c.SecretAccessKey: "MINIO_SECRET_ACCESS_KEY",
+		&c.Bucket:          "MINIO_BUCKET",
+	} {
+		if *v == "" {
+			*v = os.Getenv(env)
+		}
+	}
+	if c.UseSSL == nil {
+		if v := os.Getenv("MINIO_USE_SSL"); v != "" {
+			if parsed, err := strconv.ParseBool(v); err == nil {
+				c.UseSSL = &parsed
+			} else {
+				slog.Warn("Invalid MINIO_USE_SSL value, defaulting to true", slog.String("value", v), slog.Any("error", err))
+			}
+		}
+	}
+	return c
+}
@@ -0,0 +1,68 @@
+package render
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/humanitec/canyon-cli/internal/config"
+)
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore()
+	u, err := s.Put(context.Background(), "a.html", "text/html", []byte("<p>hi</p>"))
+	assert.NoError(t, err)
+	assert.Equal(t, "memory://a.html", u)
+	o, ok := s.Get("a.html")
+	assert.True(t, ok)
+	assert.Equal(t, MemoryObject{ContentType: "text/html", Content: []byte("<p>hi</p>")}, o)
+}
+
+func TestLocalStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "renders")
+	u, err := NewLocalStore(dir).Put(context.Background(), "a.html", "text/html", []byte("<p>hi</p>"))
+	assert.NoError(t, err)
+	parsed, err := url.Parse(u)
+	assert.NoError(t, err)
+	assert.Equal(t, "file", parsed.Scheme)
+	raw, err := os.ReadFile(filepath.FromSlash(parsed.Path))
+	assert.NoError(t, err)
+	assert.Equal(t, "<p>hi</p>", string(raw))
+}
+
+func TestNewFromConfig(t *testing.T) {
+	for _, k := range []string{"MINIO_ENDPOINT", "MINIO_ACCESS_KEY_ID", "MINIO_SECRET_ACCESS_KEY", "MINIO_BUCKET", "MINIO_USE_SSL"} {
+		t.Setenv(k, "")
+	}
+
+	s, err := NewFromConfig(config.Render{})
+	assert.NoError(t, err)
+	if assert.IsType(t, &LocalStore{}, s) {
+		assert.Equal(t, defaultLocalDir(), s.(*LocalStore).Dir)
+		assert.NotEqual(t, filepath.Join(os.TempDir(), "canyon-renders"), s.(*LocalStore).Dir)
+	}
+
+	s, err = NewFromConfig(config.Render{Store: StoreMemory})
+	assert.NoError(t, err)
+	assert.IsType(t, &MemoryStore{}, s)
+
+	_, err = NewFromConfig(config.Render{Store: "ftp"})
+	assert.EqualError(t, err, "unknown render store 'ftp', expected one of local, s3, memory")
+
+	_, err = NewFromConfig(config.Render{Store: StoreS3})
+	assert.Error(t, err)
+
+	t.Setenv("MINIO_ENDPOINT", "https://minio.example.com")
+	t.Setenv("MINIO_ACCESS_KEY_ID", "key")
+	t.Setenv("MINIO_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("MINIO_BUCKET", "renders")
+	s, err = NewFromConfig(config.Render{})
+	assert.NoError(t, err)
+	if assert.IsType(t, &S3Store{}, s) {
+		assert.Equal(t, "renders", s.(*S3Store).Bucket)
+	}
+}